@@ -7,11 +7,13 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync"
 
-	"github.com/Financial-Times/draft-annotations-api/mapper"
-	"github.com/pkg/errors"
+	stderrors "errors"
 
+	"github.com/Financial-Times/draft-annotations-api/mapper"
 	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -33,9 +35,17 @@ const (
 
 	pacAnnotationLifecycle       = "pac"
 	v1AnnotationLifecycle        = "v1"
+	v2AnnotationLifecycle        = "v2"
 	nextVideoAnnotationLifecycle = "next-video"
 )
 
+var (
+	// DefaultLifecycles are the lifecycles of the published annotations returned by GetAll.
+	DefaultLifecycles = []string{pacAnnotationLifecycle, v1AnnotationLifecycle, v2AnnotationLifecycle, nextVideoAnnotationLifecycle}
+	// DefaultEditableLifecycles are the lifecycles of the editorially curated annotations returned by GetEditable.
+	DefaultEditableLifecycles = []string{pacAnnotationLifecycle, v1AnnotationLifecycle, nextVideoAnnotationLifecycle}
+)
+
 // UPPError encapsulates error information for errors originating from calls to UPP annotations endpoint.
 type UPPError struct {
 	msg     string
@@ -65,26 +75,193 @@ func (ue UPPError) UPPBody() []byte {
 
 // UPPAnnotationsAPI retrieves published annotations from UPP.
 type UPPAnnotationsAPI struct {
-	endpointTemplate string
-	apiKey           string
-	httpClient       *http.Client
+	endpointTemplate   string
+	apiKey             string
+	httpClient         *http.Client
+	lifecycles         []string
+	editableLifecycles []string
+
+	sync.Mutex
+	// calls are the calls to UPP in flight by content and lifecycle, shared by the identical calls made meanwhile.
+	calls map[string]*lifecycleCall
+}
+
+type lifecycleCall struct {
+	done        chan struct{}
+	annotations []Annotation
+	err         error
+}
+
+// UPPAnnotationsAPIOption configures optional behaviour of the UPPAnnotationsAPI.
+type UPPAnnotationsAPIOption func(*UPPAnnotationsAPI)
+
+// WithLifecycles sets the lifecycles of the annotations returned by GetAll, DefaultLifecycles by default.
+func WithLifecycles(lifecycles []string) UPPAnnotationsAPIOption {
+	return func(api *UPPAnnotationsAPI) {
+		api.lifecycles = lifecycles
+	}
+}
+
+// WithEditableLifecycles sets the lifecycles of the annotations returned by GetEditable, DefaultEditableLifecycles by default.
+func WithEditableLifecycles(lifecycles []string) UPPAnnotationsAPIOption {
+	return func(api *UPPAnnotationsAPI) {
+		api.editableLifecycles = lifecycles
+	}
 }
 
 // NewUPPAnnotationsAPI initializes UPPAnnotationsAPI by given http client,
 // the url of the UPP public endpoint for getting published annotations and UPP API key.
-func NewUPPAnnotationsAPI(client *http.Client, endpoint string, apiKey string) *UPPAnnotationsAPI {
-	return &UPPAnnotationsAPI{endpointTemplate: endpoint, apiKey: apiKey, httpClient: client}
+func NewUPPAnnotationsAPI(client *http.Client, endpoint string, apiKey string, opts ...UPPAnnotationsAPIOption) *UPPAnnotationsAPI {
+	api := &UPPAnnotationsAPI{
+		endpointTemplate:   endpoint,
+		apiKey:             apiKey,
+		httpClient:         client,
+		lifecycles:         DefaultLifecycles,
+		editableLifecycles: DefaultEditableLifecycles,
+		calls:              make(map[string]*lifecycleCall),
+	}
+	for _, opt := range opts {
+		opt(api)
+	}
+	return api
 }
 
-// GetAll retrieves the list of published annotations for given contentUUID.
-// The returned list contains the annotations returned by UPP without filtering.
+// GetAll retrieves the list of published annotations of all the lifecycles for given contentUUID,
+// each carrying the lifecycle it comes from, see GetByLifecycles.
 func (api *UPPAnnotationsAPI) GetAll(ctx context.Context, contentUUID string) ([]Annotation, error) {
-	return api.getAnnotations(ctx, contentUUID)
+	return api.GetByLifecycles(ctx, contentUUID, api.lifecycles)
+}
+
+// GetEditable retrieves the list of published annotations of the editorially curated lifecycles for given contentUUID,
+// each carrying the lifecycle it comes from, see GetByLifecycles.
+func (api *UPPAnnotationsAPI) GetEditable(ctx context.Context, contentUUID string) ([]Annotation, error) {
+	return api.GetByLifecycles(ctx, contentUUID, api.editableLifecycles)
 }
 
-// GetAllButV2 retrieves the list of published annotations for given contentUUID but filtering v2 annotations.
-func (api *UPPAnnotationsAPI) GetAllButV2(ctx context.Context, contentUUID string) ([]Annotation, error) {
-	return api.getAnnotations(ctx, contentUUID, pacAnnotationLifecycle, v1AnnotationLifecycle, nextVideoAnnotationLifecycle)
+// PartialLifecyclesError tells that the annotations of some of the lifecycles could not be retrieved from UPP.
+// GetByLifecycles returns it together with the annotations of the other lifecycles.
+type PartialLifecyclesError struct {
+	Lifecycles []string
+	Err        error
+}
+
+func (e *PartialLifecyclesError) Error() string {
+	return fmt.Sprintf("failed to retrieve the annotations of lifecycles %v: %v", e.Lifecycles, e.Err)
+}
+
+func (e *PartialLifecyclesError) Unwrap() error {
+	return e.Err
+}
+
+// GetByLifecycles retrieves the list of published annotations of the given lifecycles for given contentUUID.
+// UPP does not tell which lifecycle an annotation belongs to, so each lifecycle is requested on its own
+// and each annotation carries the lifecycle it comes from. An annotation in several lifecycles is returned once,
+// with the first of them in the given order. The call for a lifecycle is shared with the identical calls in flight,
+// e.g. those of GetAll and GetEditable for the same content.
+// When only some of the lifecycles fail, the annotations of the others are returned with a *PartialLifecyclesError.
+func (api *UPPAnnotationsAPI) GetByLifecycles(ctx context.Context, contentUUID string, lifecycles []string) ([]Annotation, error) {
+	results := make([][]Annotation, len(lifecycles))
+	errs := make([]error, len(lifecycles))
+
+	var wg sync.WaitGroup
+	for i, lc := range lifecycles {
+		wg.Add(1)
+		go func(i int, lc string) {
+			defer wg.Done()
+			results[i], errs[i] = api.getLifecycle(ctx, contentUUID, lc)
+		}(i, lc)
+	}
+	wg.Wait()
+
+	return mergeLifecycles(lifecycles, results, errs)
+}
+
+// mergeLifecycles merges the annotations of each lifecycle, tagging them with their lifecycle.
+// Lifecycles without annotations are skipped, unless none of them has annotations.
+// Failed lifecycles are skipped too, and reported by a *PartialLifecyclesError, unless no annotations are left.
+func mergeLifecycles(lifecycles []string, results [][]Annotation, errs []error) ([]Annotation, error) {
+	var notFound error
+	partial := &PartialLifecyclesError{}
+	seen := make(map[Annotation]struct{})
+	merged := []Annotation{}
+	for i, lc := range lifecycles {
+		if errs[i] != nil {
+			var uppErr UPPError
+			if stderrors.As(errs[i], &uppErr) && uppErr.Status() == http.StatusNotFound {
+				if notFound == nil {
+					notFound = errs[i]
+				}
+				continue
+			}
+			if partial.Err == nil {
+				partial.Err = errs[i]
+			}
+			partial.Lifecycles = append(partial.Lifecycles, lc)
+			continue
+		}
+		for _, ann := range results[i] {
+			key := Annotation{Predicate: ann.Predicate, ConceptId: ann.ConceptId}
+			if _, found := seen[key]; found {
+				continue
+			}
+			seen[key] = struct{}{}
+			ann.Lifecycle = lc
+			merged = append(merged, ann)
+		}
+	}
+
+	if len(merged) == 0 {
+		if partial.Err != nil {
+			return nil, partial.Err
+		}
+		if notFound != nil {
+			return nil, notFound
+		}
+	}
+	if partial.Err != nil {
+		return merged, partial
+	}
+	return merged, nil
+}
+
+// getLifecycle retrieves the published annotations of a single lifecycle, or waits for the identical call in flight.
+// The shared call runs with a context of its own, with the deadline of ctx, so that it is not cancelled
+// with the request that started it. The returned annotations must not be modified.
+func (api *UPPAnnotationsAPI) getLifecycle(ctx context.Context, contentUUID string, lifecycle string) ([]Annotation, error) {
+	key := contentUUID + "/" + lifecycle
+
+	api.Lock()
+	call, found := api.calls[key]
+	if !found {
+		call = &lifecycleCall{done: make(chan struct{})}
+		api.calls[key] = call
+	}
+	api.Unlock()
+
+	if !found {
+		callCtx := context.Background()
+		if tID, err := tidUtils.GetTransactionIDFromContext(ctx); err == nil {
+			callCtx = tidUtils.TransactionAwareContext(callCtx, tID)
+		}
+		cancel := func() {}
+		if deadline, ok := ctx.Deadline(); ok {
+			callCtx, cancel = context.WithDeadline(callCtx, deadline)
+		}
+		call.annotations, call.err = api.getAnnotations(callCtx, contentUUID, lifecycle)
+		cancel()
+
+		api.Lock()
+		delete(api.calls, key)
+		api.Unlock()
+		close(call.done)
+	}
+
+	select {
+	case <-call.done:
+		return call.annotations, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
 func (api *UPPAnnotationsAPI) getAnnotations(ctx context.Context, contentUUID string, lifecycles ...string) ([]Annotation, error) {
@@ -99,11 +276,16 @@ func (api *UPPAnnotationsAPI) getAnnotations(ctx context.Context, contentUUID st
 		return nil, errors.Wrap(err, "failed to read UPP response body")
 	}
 
-	if uppResponse.StatusCode != http.StatusOK {
-		if uppResponse.StatusCode == http.StatusBadRequest {
+	return DecodeUPPAnnotations(uppResponse.StatusCode, respBody)
+}
+
+// DecodeUPPAnnotations converts a response of the UPP annotations endpoint, given its status code and body, to PAC annotations.
+func DecodeUPPAnnotations(status int, respBody []byte) ([]Annotation, error) {
+	if status != http.StatusOK {
+		if status == http.StatusBadRequest {
 			return nil, UPPError{msg: UPPBadRequestMsg, status: http.StatusBadRequest, uppBody: respBody}
 		}
-		if uppResponse.StatusCode == http.StatusNotFound {
+		if status == http.StatusNotFound {
 			return nil, UPPError{msg: UPPNotFoundMsg, status: http.StatusNotFound, uppBody: respBody}
 		}
 
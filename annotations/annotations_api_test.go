@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
 	"testing"
 	"time"
 
@@ -262,6 +263,189 @@ func TestGetAnnotationsHappy(t *testing.T) {
 	}
 }
 
+func TestGetByLifecycles(t *testing.T) {
+	bodies := map[string]string{
+		"pac": `[{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://api.ft.com/things/dd158946-e88b-3a85-abe4-5848319501ce",
+			"types": ["http://www.ft.com/ontology/Location"],
+			"prefLabel": "Canada"
+		}]`,
+		"v2": `[{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://api.ft.com/things/dd158946-e88b-3a85-abe4-5848319501ce",
+			"types": ["http://www.ft.com/ontology/Location"],
+			"prefLabel": "Canada"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/mentions",
+			"id": "http://api.ft.com/things/a579350c-61ce-4c00-97ca-ddaa2e0cacf6",
+			"types": ["http://www.ft.com/ontology/Topic"],
+			"prefLabel": "Trade"
+		}]`,
+	}
+	var requested []string
+	var mu sync.Mutex
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lc := r.URL.Query().Get("lifecycle")
+		mu.Lock()
+		requested = append(requested, lc)
+		mu.Unlock()
+		switch {
+		case lc == "v1":
+			w.WriteHeader(http.StatusNotFound)
+		case lc == "next-video":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			_, _ = w.Write([]byte(bodies[lc]))
+		}
+	}))
+	defer s.Close()
+
+	api := NewUPPAnnotationsAPI(testClient, s.URL+"/content/%v/annotations", testAPIKey)
+
+	list, err := api.GetByLifecycles(context.Background(), testContentUUID, []string{"pac", "v1", "v2"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"pac", "v1", "v2"}, requested)
+	assert.Equal(t, []Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/dd158946-e88b-3a85-abe4-5848319501ce",
+			Type:      "http://www.ft.com/ontology/Location",
+			PrefLabel: "Canada",
+			Lifecycle: "pac",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/a579350c-61ce-4c00-97ca-ddaa2e0cacf6",
+			Type:      "http://www.ft.com/ontology/Topic",
+			PrefLabel: "Trade",
+			Lifecycle: "v2",
+		},
+	}, list, "the annotations in several lifecycles come from the first of them")
+
+	_, err = api.GetByLifecycles(context.Background(), testContentUUID, []string{"v1"})
+	var uppErr UPPError
+	assert.True(t, errors.As(err, &uppErr))
+	assert.Equal(t, http.StatusNotFound, uppErr.Status(), "no lifecycle has annotations")
+
+	list, err = api.GetByLifecycles(context.Background(), testContentUUID, []string{"pac", "next-video"})
+	var partialErr *PartialLifecyclesError
+	assert.True(t, errors.As(err, &partialErr))
+	assert.Equal(t, []string{"next-video"}, partialErr.Lifecycles)
+	assert.True(t, errors.As(err, &uppErr))
+	assert.Equal(t, http.StatusServiceUnavailable, uppErr.Status())
+	assert.Len(t, list, 1, "the annotations of the other lifecycles are returned")
+	assert.Equal(t, "pac", list[0].Lifecycle)
+
+	list, err = api.GetByLifecycles(context.Background(), testContentUUID, []string{"v1", "next-video"})
+	assert.False(t, errors.As(err, &partialErr), "no annotations are left")
+	assert.True(t, errors.As(err, &uppErr))
+	assert.Equal(t, http.StatusServiceUnavailable, uppErr.Status())
+	assert.Nil(t, list)
+}
+
+func TestGetAllAndGetEditableTagLifecycles(t *testing.T) {
+	var queries []string
+	var mu sync.Mutex
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		_, _ = w.Write([]byte(`[{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://api.ft.com/things/dd158946-e88b-3a85-abe4-5848319501ce",
+			"types": ["http://www.ft.com/ontology/Location"],
+			"prefLabel": "Canada"
+		}]`))
+	}))
+	defer s.Close()
+
+	tests := map[string]struct {
+		opts              []UPPAnnotationsAPIOption
+		get               func(api *UPPAnnotationsAPI) ([]Annotation, error)
+		expectedQueries   []string
+		expectedLifecycle string
+	}{
+		"all of the default lifecycles": {
+			get: func(api *UPPAnnotationsAPI) ([]Annotation, error) {
+				return api.GetAll(context.Background(), testContentUUID)
+			},
+			expectedQueries:   []string{"lifecycle=pac", "lifecycle=v1", "lifecycle=v2", "lifecycle=next-video"},
+			expectedLifecycle: "pac",
+		},
+		"all of the configured lifecycles": {
+			opts: []UPPAnnotationsAPIOption{WithLifecycles([]string{"v2", "pac"})},
+			get: func(api *UPPAnnotationsAPI) ([]Annotation, error) {
+				return api.GetAll(context.Background(), testContentUUID)
+			},
+			expectedQueries:   []string{"lifecycle=v2", "lifecycle=pac"},
+			expectedLifecycle: "v2",
+		},
+		"editable lifecycles": {
+			get: func(api *UPPAnnotationsAPI) ([]Annotation, error) {
+				return api.GetEditable(context.Background(), testContentUUID)
+			},
+			expectedQueries:   []string{"lifecycle=pac", "lifecycle=v1", "lifecycle=next-video"},
+			expectedLifecycle: "pac",
+		},
+		"configured editable lifecycles": {
+			opts: []UPPAnnotationsAPIOption{WithEditableLifecycles([]string{"v1", "pac"})},
+			get: func(api *UPPAnnotationsAPI) ([]Annotation, error) {
+				return api.GetEditable(context.Background(), testContentUUID)
+			},
+			expectedQueries:   []string{"lifecycle=v1", "lifecycle=pac"},
+			expectedLifecycle: "v1",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			queries = nil
+			api := NewUPPAnnotationsAPI(testClient, s.URL+"/content/%v/annotations", testAPIKey, test.opts...)
+
+			list, err := test.get(api)
+			assert.NoError(t, err)
+			assert.ElementsMatch(t, test.expectedQueries, queries)
+			assert.Len(t, list, 1)
+			assert.Equal(t, test.expectedLifecycle, list[0].Lifecycle, "the annotation comes from the first of its lifecycles")
+		})
+	}
+}
+
+func TestGetByLifecyclesSharesTheCallsInFlight(t *testing.T) {
+	var requested []string
+	var mu sync.Mutex
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Query().Get("lifecycle"))
+		mu.Unlock()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer s.Close()
+
+	api := NewUPPAnnotationsAPI(testClient, s.URL+"/content/%v/annotations", testAPIKey)
+	inFlight := &lifecycleCall{done: make(chan struct{})}
+	api.calls[testContentUUID+"/pac"] = inFlight
+
+	done := make(chan struct{})
+	var list []Annotation
+	var err error
+	go func() {
+		defer close(done)
+		list, err = api.GetByLifecycles(context.Background(), testContentUUID, []string{"pac", "v2"})
+	}()
+
+	inFlight.annotations = []Annotation{{Predicate: "http://www.ft.com/ontology/annotation/about", ConceptId: "http://www.ft.com/thing/dd158946-e88b-3a85-abe4-5848319501ce"}}
+	close(inFlight.done)
+	<-done
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v2"}, requested, "pac is served by the call in flight")
+	assert.Len(t, list, 1)
+	assert.Equal(t, "pac", list[0].Lifecycle)
+}
+
 func newAnnotationsAPIServerMock(t *testing.T, tid string, uuid string, lifecycles string, status int, body string) *httptest.Server {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/content/"+uuid+annotationsEndpoint, r.URL.Path)
@@ -312,3 +496,51 @@ func newAnnotationsAPIGTGServerMock(t *testing.T, status int, body string) *http
 	}))
 	return ts
 }
+
+func TestDecodeUPPAnnotationsWithMalformedAnnotations(t *testing.T) {
+	body := `[
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://api.ft.com/things/11111111-1111-1111-1111-111111111111", "types": []},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://api.ft.com/things/22222222-2222-2222-2222-222222222222", "types": ["http://www.ft.com/ontology/core/Thing", 42]},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://api.ft.com/things/33333333-3333-3333-3333-333333333333", "types": "http://www.ft.com/ontology/Topic"},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "types": ["http://www.ft.com/ontology/Topic"]},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": 42, "types": ["http://www.ft.com/ontology/Topic"]},
+		{"predicate": true, "id": "http://api.ft.com/things/44444444-4444-4444-4444-444444444444", "types": ["http://www.ft.com/ontology/Topic"]},
+		{"predicate": "http://www.ft.com/ontology/annotation/mentions", "id": "http://api.ft.com/things/55555555-5555-5555-5555-555555555555", "types": ["http://www.ft.com/ontology/Person"]}
+	]`
+
+	actual, err := DecodeUPPAnnotations(http.StatusOK, []byte(body))
+	assert.NoError(t, err)
+	assert.Equal(t, []Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/11111111-1111-1111-1111-111111111111",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/22222222-2222-2222-2222-222222222222",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/33333333-3333-3333-3333-333333333333",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/55555555-5555-5555-5555-555555555555",
+			Type:      "http://www.ft.com/ontology/Person",
+		},
+	}, actual, "the annotations without valid types are read without a type, the ones without predicate or ID are dropped")
+}
+
+func TestDecodeUPPAnnotationsOnlyMalformedAnnotations(t *testing.T) {
+	body := `[
+		{"predicate": true, "id": "http://api.ft.com/things/11111111-1111-1111-1111-111111111111", "types": []},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "types": ["http://www.ft.com/ontology/Topic"]}
+	]`
+
+	_, err := DecodeUPPAnnotations(http.StatusOK, []byte(body))
+	var uppErr UPPError
+	if assert.True(t, errors.As(err, &uppErr)) {
+		assert.Equal(t, NoAnnotationsMsg, uppErr.msg)
+		assert.Equal(t, http.StatusNotFound, uppErr.status)
+	}
+}
@@ -3,6 +3,8 @@ package annotations
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -35,6 +37,7 @@ func NewRW(client *http.Client, endpoint string) RW {
 
 var ErrUnexpectedStatusRead = errors.New("annotations RW returned an unexpected HTTP status code in read operation")
 var ErrUnexpectedStatusWrite = errors.New("annotations RW returned an unexpected HTTP status code in write operation")
+var ErrDocumentHashConflict = fmt.Errorf("previous document hash does not match the stored draft annotations: %w", ErrUnexpectedStatusWrite)
 var ErrGTGNotOK = errors.New("gtg returned a non-200 HTTP status")
 
 func (rw *annotationsRW) Read(ctx context.Context, contentUUID string) (*Annotations, string, bool, error) {
@@ -120,11 +123,24 @@ func (rw *annotationsRW) Write(ctx context.Context, contentUUID string, annotati
 	case http.StatusOK, http.StatusCreated:
 		newHash := resp.Header.Get(DocumentHashHeader)
 		return newHash, nil
+	case http.StatusConflict:
+		return "", fmt.Errorf("status %d: %w", resp.StatusCode, ErrDocumentHashConflict)
 	default:
 		return "", fmt.Errorf("status %d: %w", resp.StatusCode, ErrUnexpectedStatusWrite)
 	}
 }
 
+// marshalDocument encodes the draft annotations as they are stored, along with their document hash.
+// Local RW implementations use it to reproduce the hashing of the Generic RW Aurora, SHA224 + Hex of the stored body.
+func marshalDocument(annotations *Annotations) ([]byte, string, error) {
+	body, err := json.Marshal(annotations)
+	if err != nil {
+		return nil, "", err
+	}
+	hash := sha256.Sum224(body)
+	return body, hex.EncodeToString(hash[:]), nil
+}
+
 func (rw *annotationsRW) Endpoint() string {
 	return rw.endpoint
 }
@@ -0,0 +1,78 @@
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+const inMemoryRWEndpoint = "memory"
+
+type storedDocument struct {
+	body []byte
+	hash string
+}
+
+type inMemoryRW struct {
+	sync.RWMutex
+	documents map[string]storedDocument
+}
+
+// NewInMemoryRW returns an RW keeping draft annotations in memory, honouring the document hash semantics of the Generic RW Aurora.
+// It is meant for local development and integration tests.
+func NewInMemoryRW() RW {
+	return &inMemoryRW{documents: make(map[string]storedDocument)}
+}
+
+func (rw *inMemoryRW) Read(ctx context.Context, contentUUID string) (*Annotations, string, bool, error) {
+	rw.RLock()
+	doc, found := rw.documents[contentUUID]
+	rw.RUnlock()
+	if !found {
+		return nil, "", false, nil
+	}
+
+	var annotations Annotations
+	if err := json.Unmarshal(doc.body, &annotations); err != nil {
+		return nil, "", false, err
+	}
+	return &annotations, doc.hash, true, nil
+}
+
+func (rw *inMemoryRW) Write(ctx context.Context, contentUUID string, annotations *Annotations, hash string) (string, error) {
+	body, newHash, err := marshalDocument(annotations)
+	if err != nil {
+		return "", err
+	}
+
+	rw.Lock()
+	defer rw.Unlock()
+	if hash != "" {
+		if doc, found := rw.documents[contentUUID]; !found || doc.hash != hash {
+			return "", ErrDocumentHashConflict
+		}
+	}
+	rw.documents[contentUUID] = storedDocument{body: body, hash: newHash}
+	return newHash, nil
+}
+
+// ListDrafts returns the UUIDs of the contents with a draft, in ascending order.
+func (rw *inMemoryRW) ListDrafts(ctx context.Context) ([]string, error) {
+	rw.RLock()
+	defer rw.RUnlock()
+	contentUUIDs := make([]string, 0, len(rw.documents))
+	for contentUUID := range rw.documents {
+		contentUUIDs = append(contentUUIDs, contentUUID)
+	}
+	sort.Strings(contentUUIDs)
+	return contentUUIDs, nil
+}
+
+func (rw *inMemoryRW) Endpoint() string {
+	return inMemoryRWEndpoint
+}
+
+func (rw *inMemoryRW) GTG() error {
+	return nil
+}
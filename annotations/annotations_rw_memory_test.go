@@ -0,0 +1,60 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryRW(t *testing.T) {
+	testLocalRW(t, NewInMemoryRW())
+}
+
+// testLocalRW checks that a local RW implementation follows the document hash semantics of the Generic RW Aurora.
+func testLocalRW(t *testing.T, rw RW) {
+	ctx := context.Background()
+
+	_, _, found, err := rw.Read(ctx, testContentUUID)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	_, err = rw.Write(ctx, testContentUUID, &expectedCanonicalizedAnnotations, "a-hash-for-a-missing-draft")
+	assert.True(t, errors.Is(err, ErrDocumentHashConflict))
+
+	hash, err := rw.Write(ctx, testContentUUID, &expectedCanonicalizedAnnotations, "")
+	assert.NoError(t, err)
+	assert.Len(t, hash, 56)
+
+	actual, actualHash, found, err := rw.Read(ctx, testContentUUID)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, expectedCanonicalizedAnnotations, *actual)
+	assert.Equal(t, hash, actualHash)
+
+	updated := &Annotations{Annotations: expectedCanonicalizedAnnotations.Annotations[:1]}
+	_, err = rw.Write(ctx, testContentUUID, updated, "a-stale-hash")
+	assert.True(t, errors.Is(err, ErrDocumentHashConflict))
+	assert.True(t, errors.Is(err, ErrUnexpectedStatusWrite))
+
+	newHash, err := rw.Write(ctx, testContentUUID, updated, hash)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hash, newHash)
+
+	_, err = rw.Write(ctx, testContentUUID, &expectedCanonicalizedAnnotations, hash)
+	assert.True(t, errors.Is(err, ErrDocumentHashConflict))
+
+	actual, actualHash, found, err = rw.Read(ctx, testContentUUID)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, *updated, *actual)
+	assert.Equal(t, newHash, actualHash)
+
+	contentUUIDs, err := rw.(DraftLister).ListDrafts(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testContentUUID}, contentUUIDs)
+
+	assert.NoError(t, rw.GTG())
+	assert.NotEmpty(t, rw.Endpoint())
+}
@@ -0,0 +1,120 @@
+package annotations
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	// registers the pure Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+const (
+	createDraftAnnotationsTable = `CREATE TABLE IF NOT EXISTS draft_annotations (
+		uuid TEXT PRIMARY KEY,
+		body TEXT NOT NULL,
+		hash TEXT NOT NULL
+	)`
+	selectDraftAnnotations = `SELECT body, hash FROM draft_annotations WHERE uuid = ?`
+	upsertDraftAnnotations = `INSERT INTO draft_annotations (uuid, body, hash) VALUES (?, ?, ?)
+		ON CONFLICT(uuid) DO UPDATE SET body = excluded.body, hash = excluded.hash`
+	updateDraftAnnotations = `UPDATE draft_annotations SET body = ?, hash = ? WHERE uuid = ? AND hash = ?`
+	selectDraftUUIDs       = `SELECT uuid FROM draft_annotations ORDER BY uuid`
+)
+
+type sqliteRW struct {
+	path string
+	db   *sql.DB
+}
+
+// NewSQLiteRW returns an RW keeping draft annotations in an embedded SQLite database at the given path,
+// honouring the document hash semantics of the Generic RW Aurora.
+func NewSQLiteRW(path string) (RW, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	// SQLite allows a single writer at a time
+	db.SetMaxOpenConns(1)
+
+	if _, err := db.Exec(createDraftAnnotationsTable); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create draft annotations table: %w", err)
+	}
+	return &sqliteRW{path: path, db: db}, nil
+}
+
+func (rw *sqliteRW) Read(ctx context.Context, contentUUID string) (*Annotations, string, bool, error) {
+	var body, hash string
+	err := rw.db.QueryRowContext(ctx, selectDraftAnnotations, contentUUID).Scan(&body, &hash)
+	if err == sql.ErrNoRows {
+		return nil, "", false, nil
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	var annotations Annotations
+	if err := json.Unmarshal([]byte(body), &annotations); err != nil {
+		return nil, "", false, err
+	}
+	return &annotations, hash, true, nil
+}
+
+func (rw *sqliteRW) Write(ctx context.Context, contentUUID string, annotations *Annotations, hash string) (string, error) {
+	body, newHash, err := marshalDocument(annotations)
+	if err != nil {
+		return "", err
+	}
+
+	if hash == "" {
+		if _, err := rw.db.ExecContext(ctx, upsertDraftAnnotations, contentUUID, string(body), newHash); err != nil {
+			return "", err
+		}
+		return newHash, nil
+	}
+
+	res, err := rw.db.ExecContext(ctx, updateDraftAnnotations, string(body), newHash, contentUUID, hash)
+	if err != nil {
+		return "", err
+	}
+	updated, err := res.RowsAffected()
+	if err != nil {
+		return "", err
+	}
+	if updated == 0 {
+		return "", ErrDocumentHashConflict
+	}
+	return newHash, nil
+}
+
+// ListDrafts returns the UUIDs of the contents with a draft, in ascending order.
+func (rw *sqliteRW) ListDrafts(ctx context.Context) ([]string, error) {
+	rows, err := rw.db.QueryContext(ctx, selectDraftUUIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contentUUIDs := []string{}
+	for rows.Next() {
+		var contentUUID string
+		if err := rows.Scan(&contentUUID); err != nil {
+			return nil, err
+		}
+		contentUUIDs = append(contentUUIDs, contentUUID)
+	}
+	return contentUUIDs, rows.Err()
+}
+
+func (rw *sqliteRW) Endpoint() string {
+	return "sqlite://" + rw.path
+}
+
+func (rw *sqliteRW) GTG() error {
+	if err := rw.db.Ping(); err != nil {
+		return fmt.Errorf("GTG: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package annotations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLiteRW(t *testing.T) {
+	dir, err := ioutil.TempDir("", "draft-annotations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "draft-annotations.db")
+	rw, err := NewSQLiteRW(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testLocalRW(t, rw)
+	assert.Equal(t, "sqlite://"+path, rw.Endpoint())
+}
+
+func TestSQLiteRWInvalidPath(t *testing.T) {
+	_, err := NewSQLiteRW(filepath.Join("not", "an", "existing", "dir", "draft-annotations.db"))
+	assert.Error(t, err)
+}
@@ -186,6 +186,19 @@ func TestUnhappyWriteStatus500(t *testing.T) {
 	assert.True(t, errors.Is(err, ErrUnexpectedStatusWrite))
 }
 
+func TestUnhappyWriteStatusConflict(t *testing.T) {
+	tid := tidUtils.NewTransactionID()
+	oldHash := randomdata.RandStringRunes(56)
+	s := newAnnotationsRWServerMock(t, http.MethodPut, http.StatusConflict, testRWBody, oldHash, "", tid)
+	defer s.Close()
+
+	rw := NewRW(testClient, s.URL)
+	ctx := tidUtils.TransactionAwareContext(context.Background(), tid)
+	_, err := rw.Write(ctx, testContentUUID, &expectedCanonicalizedAnnotations, oldHash)
+	assert.True(t, errors.Is(err, ErrDocumentHashConflict))
+	assert.True(t, errors.Is(err, ErrUnexpectedStatusWrite))
+}
+
 func TestWriteHTTPRequestError(t *testing.T) {
 	tid := tidUtils.NewTransactionID()
 	oldHash := randomdata.RandStringRunes(56)
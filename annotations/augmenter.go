@@ -47,12 +47,7 @@ func (a *Augmenter) AugmentAnnotations(ctx context.Context, canonicalAnnotations
 		uuid := extractUUID(ann.ConceptId)
 		concept, found := concepts[uuid]
 		if found {
-			ann.ConceptId = concept.ID
-			ann.ApiUrl = concept.ApiUrl
-			ann.PrefLabel = concept.PrefLabel
-			ann.IsFTAuthor = concept.IsFTAuthor
-			ann.Type = concept.Type
-			augmentedAnnotations = append(augmentedAnnotations, ann)
+			augmentedAnnotations = append(augmentedAnnotations, augment(ann, concept, tid))
 		} else {
 			log.WithField(tidUtils.TransactionIDKey, tid).
 				WithField("conceptId", ann.ConceptId).
@@ -64,6 +59,76 @@ func (a *Augmenter) AugmentAnnotations(ctx context.Context, canonicalAnnotations
 	return augmentedAnnotations, nil
 }
 
+// ConceptLookup looks up the last known data of concepts without calling the concepts API.
+type ConceptLookup interface {
+	Lookup(ids []string) map[string]concept.Concept
+}
+
+// DegradedAugmenter augments annotations with the last known concept data, for when the concepts API is unavailable.
+// Unlike Augmenter, it keeps the annotations of unknown concepts, as they are, rather than removing them.
+type DegradedAugmenter struct {
+	lastKnown ConceptLookup
+}
+
+func NewDegradedAugmenter(lastKnown ConceptLookup) *DegradedAugmenter {
+	return &DegradedAugmenter{lastKnown}
+}
+
+func (a *DegradedAugmenter) AugmentAnnotations(ctx context.Context, canonicalAnnotations []Annotation) ([]Annotation, error) {
+	tid, err := tidUtils.GetTransactionIDFromContext(ctx)
+	if err != nil {
+		tid = tidUtils.NewTransactionID()
+	}
+
+	dedupedCanonical := dedupeCanonicalAnnotations(canonicalAnnotations)
+	dedupedCanonical = filterOutInvalidPredicates(dedupedCanonical)
+
+	concepts := a.lastKnown.Lookup(getConceptUUIDs(dedupedCanonical))
+
+	augmentedAnnotations := make([]Annotation, 0, len(dedupedCanonical))
+	missing := 0
+	for _, ann := range dedupedCanonical {
+		concept, found := concepts[extractUUID(ann.ConceptId)]
+		if found {
+			ann = augment(ann, concept, tid)
+		} else {
+			missing++
+		}
+		augmentedAnnotations = append(augmentedAnnotations, ann)
+	}
+
+	log.WithField(tidUtils.TransactionIDKey, tid).
+		WithField("unknownConcepts", missing).
+		Warn("Annotations augmented with the last known concept data")
+	return augmentedAnnotations, nil
+}
+
+func augment(ann Annotation, concept concept.Concept, tid string) Annotation {
+	if extractUUID(ann.ConceptId) != extractUUID(concept.ID) {
+		log.WithField(tidUtils.TransactionIDKey, tid).
+			WithField("conceptId", ann.ConceptId).
+			WithField("canonicalConceptId", concept.ID).
+			Info("Concept has been merged, the annotation will refer to the canonical concept.")
+		ann.MergedFrom = ann.ConceptId
+	}
+	ann.ConceptId = concept.ID
+	ann.ApiUrl = concept.ApiUrl
+	ann.PrefLabel = concept.PrefLabel
+	ann.IsFTAuthor = concept.IsFTAuthor
+	ann.Type = concept.Type
+	ann.ConceptExpansion = nil
+	if len(concept.Aliases) > 0 || concept.DescriptionXML != "" || concept.ImageURL != "" || len(concept.BroaderConcepts) > 0 || concept.IsDeprecated {
+		ann.ConceptExpansion = &ConceptExpansion{
+			Aliases:         concept.Aliases,
+			DescriptionXML:  concept.DescriptionXML,
+			ImageURL:        concept.ImageURL,
+			BroaderConcepts: concept.BroaderConcepts,
+			IsDeprecated:    concept.IsDeprecated,
+		}
+	}
+	return ann
+}
+
 func dedupeCanonicalAnnotations(annotations []Annotation) []Annotation {
 	var empty struct{}
 	var deduped []Annotation
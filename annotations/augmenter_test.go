@@ -94,6 +94,7 @@ var expectedAugmentedAnnotations = []Annotation{
 		Type:       "http://www.ft.com/ontology/person/Person",
 		PrefLabel:  "Frederick Stapleton",
 		IsFTAuthor: false,
+		MergedFrom: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
 	},
 }
 
@@ -260,6 +261,90 @@ func TestAugmentAnnotationsWithInvalidConceptID(t *testing.T) {
 	conceptRead.AssertExpectations(t)
 }
 
+func TestDegradedAugmentAnnotations(t *testing.T) {
+	lastKnown := conceptLookupMock(func(ids []string) map[string]concept.Concept {
+		assert.ElementsMatch(t, []string{"b224ad07-c818-3ad6-94af-a4d351dbb619", "1a2a1a0a-7199-38b8-8a73-e651e2172471"}, ids)
+		return map[string]concept.Concept{
+			"b224ad07-c818-3ad6-94af-a4d351dbb619": testConcepts["b224ad07-c818-3ad6-94af-a4d351dbb619"],
+		}
+	})
+	a := NewDegradedAugmenter(lastKnown)
+	ctx := tidUtils.TransactionAwareContext(context.Background(), tidUtils.NewTransactionID())
+
+	annotations, err := a.AugmentAnnotations(ctx, []Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/classification/isClassifiedBy",
+			ConceptId: "http://www.ft.com/thing/b224ad07-c818-3ad6-94af-a4d351dbb619",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/invalid",
+			ConceptId: "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Annotation{
+		expectedAugmentedAnnotations[0],
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+		},
+	}, annotations)
+}
+
+func TestAugmentAnnotationsWithConceptExpansion(t *testing.T) {
+	conceptRead := new(ConceptReadAPIMock)
+	ctx := tidUtils.TransactionAwareContext(context.Background(), tidUtils.NewTransactionID())
+	conceptRead.
+		On("GetConceptsByIDs", ctx, []string{"5507ab98-b747-3ebc-b816-11603b9009f4"}).
+		Return(map[string]concept.Concept{
+			"5507ab98-b747-3ebc-b816-11603b9009f4": {
+				ID:             "http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4",
+				Type:           "http://www.ft.com/ontology/Topic",
+				PrefLabel:      "Technology sector",
+				Aliases:        []string{"Tech"},
+				ImageURL:       "http://images.ft.com/technology.png",
+				IsDeprecated:   true,
+				DescriptionXML: "<p>Technology</p>",
+			},
+		}, nil)
+	a := NewAugmenter(conceptRead)
+
+	annotations, err := a.AugmentAnnotations(ctx, []Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4",
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4",
+			Type:      "http://www.ft.com/ontology/Topic",
+			PrefLabel: "Technology sector",
+			ConceptExpansion: &ConceptExpansion{
+				Aliases:        []string{"Tech"},
+				DescriptionXML: "<p>Technology</p>",
+				ImageURL:       "http://images.ft.com/technology.png",
+				IsDeprecated:   true,
+			},
+		},
+	}, annotations)
+	conceptRead.AssertExpectations(t)
+}
+
+type conceptLookupMock func(ids []string) map[string]concept.Concept
+
+func (m conceptLookupMock) Lookup(ids []string) map[string]concept.Concept {
+	return m(ids)
+}
+
 type ConceptReadAPIMock struct {
 	mock.Mock
 }
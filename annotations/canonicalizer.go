@@ -46,6 +46,11 @@ func (c *Canonicalizer) hash(ann []Annotation) string {
 	return hex.EncodeToString(hash.Sum(nil))
 }
 
+// Equal tells whether the two lists hold the same annotations once canonicalized, whatever their order and augmented fields.
+func (c *Canonicalizer) Equal(a []Annotation, b []Annotation) bool {
+	return c.hash(a) == c.hash(b)
+}
+
 func NewCanonicalAnnotationSorter(ann []Annotation) sort.Interface {
 	return &annotationSorter{ann}
 }
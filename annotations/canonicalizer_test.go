@@ -135,20 +135,20 @@ func TestCanonicalizer(t *testing.T) {
 
 	annotations := []Annotation{
 		{
-			mentions,
-			conceptUuid[0],
-			apiUrl[0],
-			testType,
-			prefLabel[0],
-			false,
+			Predicate:  mentions,
+			ConceptId:  conceptUuid[0],
+			ApiUrl:     apiUrl[0],
+			Type:       testType,
+			PrefLabel:  prefLabel[0],
+			IsFTAuthor: false,
 		},
 		{
-			about,
-			conceptUuid[1],
-			apiUrl[1],
-			testType,
-			prefLabel[1],
-			false,
+			Predicate:  about,
+			ConceptId:  conceptUuid[1],
+			ApiUrl:     apiUrl[1],
+			Type:       testType,
+			PrefLabel:  prefLabel[1],
+			IsFTAuthor: false,
 		},
 	}
 
@@ -199,20 +199,20 @@ func TestCanonicalizerHash(t *testing.T) {
 
 	annotations1 := []Annotation{
 		{
-			mentions,
-			conceptUuid[0],
-			apiUrl[0],
-			testType,
-			prefLabel[0],
-			false,
+			Predicate:  mentions,
+			ConceptId:  conceptUuid[0],
+			ApiUrl:     apiUrl[0],
+			Type:       testType,
+			PrefLabel:  prefLabel[0],
+			IsFTAuthor: false,
 		},
 		{
-			about,
-			conceptUuid[1],
-			apiUrl[1],
-			testType,
-			prefLabel[1],
-			false,
+			Predicate:  about,
+			ConceptId:  conceptUuid[1],
+			ApiUrl:     apiUrl[1],
+			Type:       testType,
+			PrefLabel:  prefLabel[1],
+			IsFTAuthor: false,
 		},
 	}
 
@@ -236,3 +236,23 @@ func TestCanonicalizerHash(t *testing.T) {
 	h2 := c14n.hash(annotations2)
 	assert.Equal(t, h1, h2, "canonical hash values")
 }
+
+func TestCanonicalizerEqual(t *testing.T) {
+	about := "http://www.ft.com/ontology/annotation/about"
+	mentions := "http://www.ft.com/ontology/annotation/mentions"
+	conceptID := "http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb"
+
+	stored := []Annotation{
+		{Predicate: about, ConceptId: conceptID},
+		{Predicate: mentions, ConceptId: conceptID},
+	}
+	augmented := []Annotation{
+		{Predicate: mentions, ConceptId: conceptID, PrefLabel: "FT"},
+		{Predicate: about, ConceptId: conceptID, Type: "http://www.ft.com/ontology/Topic"},
+	}
+
+	c14n := NewCanonicalizer(NewCanonicalAnnotationSorter)
+	assert.True(t, c14n.Equal(stored, augmented), "order and augmented fields should not matter")
+	assert.False(t, c14n.Equal(stored, augmented[:1]))
+	assert.True(t, c14n.Equal(nil, []Annotation{}))
+}
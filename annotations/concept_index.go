@@ -0,0 +1,93 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ConceptIndex is a secondary index of the draft annotations, telling which drafts reference a concept.
+// Concepts are indexed by UUID, whatever the form of their ID in the drafts.
+type ConceptIndex interface {
+	// Update replaces the concepts referenced by the draft of the content with the ones of the given annotations.
+	Update(ctx context.Context, contentUUID string, annotations []Annotation) error
+	// ContentUUIDs returns the UUIDs of the contents whose draft references the concept, in ascending order.
+	ContentUUIDs(ctx context.Context, conceptUUID string) ([]string, error)
+	// Reset empties the index, which is no longer complete.
+	Reset(ctx context.Context) error
+	// MarkComplete records that every draft has been indexed since the index was reset.
+	MarkComplete(ctx context.Context) error
+	// Complete tells whether every draft has been indexed, i.e. whether the index has been marked complete since it was reset.
+	// The drafts referencing a concept are missing from an incomplete index until it is rebuilt.
+	Complete(ctx context.Context) (bool, error)
+	Endpoint() string
+}
+
+// DraftLister is implemented by the RWs which can list the contents they hold a draft for.
+type DraftLister interface {
+	ListDrafts(ctx context.Context) ([]string, error)
+}
+
+// ErrDraftsNotListable is returned when rebuilding a concept index from an RW which cannot list its drafts,
+// without the contents to index.
+var ErrDraftsNotListable = errors.New("annotations RW cannot list its drafts")
+
+// RebuildConceptIndex indexes the drafts of the given contents, whose previous entries are replaced,
+// or of every draft if no content is given and the RW can list them, in which case the index is reset first,
+// and marked complete once every draft has been indexed.
+// The contents without a draft are removed from the index. It returns the number of drafts indexed.
+func RebuildConceptIndex(ctx context.Context, index ConceptIndex, rw RW, contentUUIDs []string) (int, error) {
+	full := len(contentUUIDs) == 0
+	if full {
+		lister, ok := rw.(DraftLister)
+		if !ok {
+			return 0, ErrDraftsNotListable
+		}
+		var err error
+		if contentUUIDs, err = lister.ListDrafts(ctx); err != nil {
+			return 0, fmt.Errorf("failed to list drafts: %w", err)
+		}
+		if err := index.Reset(ctx); err != nil {
+			return 0, fmt.Errorf("failed to reset the concept index: %w", err)
+		}
+	}
+
+	indexed := 0
+	for _, contentUUID := range contentUUIDs {
+		draft, _, found, err := rw.Read(ctx, contentUUID)
+		if err != nil {
+			return indexed, fmt.Errorf("failed to read draft annotations for %s: %w", contentUUID, err)
+		}
+		var list []Annotation
+		if found {
+			list = draft.Annotations
+			indexed++
+		}
+		if err := index.Update(ctx, contentUUID, list); err != nil {
+			return indexed, fmt.Errorf("failed to index draft annotations for %s: %w", contentUUID, err)
+		}
+	}
+	if full {
+		if err := index.MarkComplete(ctx); err != nil {
+			return indexed, fmt.Errorf("failed to mark the concept index complete: %w", err)
+		}
+	}
+	return indexed, nil
+}
+
+// referencedConcepts returns the UUIDs of the concepts the annotations refer to, once each.
+func referencedConcepts(annotations []Annotation) []string {
+	seen := make(map[string]struct{}, len(annotations))
+	uuids := make([]string, 0, len(annotations))
+	for _, ann := range annotations {
+		uuid := extractUUID(ann.ConceptId)
+		if uuid == "" {
+			continue
+		}
+		if _, found := seen[uuid]; !found {
+			seen[uuid] = struct{}{}
+			uuids = append(uuids, uuid)
+		}
+	}
+	return uuids
+}
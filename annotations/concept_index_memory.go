@@ -0,0 +1,84 @@
+package annotations
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type inMemoryConceptIndex struct {
+	sync.RWMutex
+	contentByConcept  map[string]map[string]struct{}
+	conceptsByContent map[string][]string
+	complete          bool
+}
+
+// NewInMemoryConceptIndex returns a ConceptIndex kept in memory, which is empty and incomplete until it is rebuilt.
+func NewInMemoryConceptIndex() ConceptIndex {
+	return &inMemoryConceptIndex{
+		contentByConcept:  make(map[string]map[string]struct{}),
+		conceptsByContent: make(map[string][]string),
+	}
+}
+
+func (idx *inMemoryConceptIndex) Update(ctx context.Context, contentUUID string, annotations []Annotation) error {
+	concepts := referencedConcepts(annotations)
+
+	idx.Lock()
+	defer idx.Unlock()
+	for _, conceptUUID := range idx.conceptsByContent[contentUUID] {
+		delete(idx.contentByConcept[conceptUUID], contentUUID)
+		if len(idx.contentByConcept[conceptUUID]) == 0 {
+			delete(idx.contentByConcept, conceptUUID)
+		}
+	}
+	if len(concepts) == 0 {
+		delete(idx.conceptsByContent, contentUUID)
+		return nil
+	}
+	for _, conceptUUID := range concepts {
+		if idx.contentByConcept[conceptUUID] == nil {
+			idx.contentByConcept[conceptUUID] = make(map[string]struct{})
+		}
+		idx.contentByConcept[conceptUUID][contentUUID] = struct{}{}
+	}
+	idx.conceptsByContent[contentUUID] = concepts
+	return nil
+}
+
+func (idx *inMemoryConceptIndex) ContentUUIDs(ctx context.Context, conceptUUID string) ([]string, error) {
+	idx.RLock()
+	defer idx.RUnlock()
+	contentUUIDs := make([]string, 0, len(idx.contentByConcept[conceptUUID]))
+	for contentUUID := range idx.contentByConcept[conceptUUID] {
+		contentUUIDs = append(contentUUIDs, contentUUID)
+	}
+	sort.Strings(contentUUIDs)
+	return contentUUIDs, nil
+}
+
+func (idx *inMemoryConceptIndex) Reset(ctx context.Context) error {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.contentByConcept = make(map[string]map[string]struct{})
+	idx.conceptsByContent = make(map[string][]string)
+	idx.complete = false
+	return nil
+}
+
+func (idx *inMemoryConceptIndex) MarkComplete(ctx context.Context) error {
+	idx.Lock()
+	defer idx.Unlock()
+	idx.complete = true
+	return nil
+}
+
+func (idx *inMemoryConceptIndex) Complete(ctx context.Context) (bool, error) {
+	idx.RLock()
+	defer idx.RUnlock()
+	return idx.complete, nil
+}
+
+func (idx *inMemoryConceptIndex) Endpoint() string {
+	return inMemoryRWEndpoint
+}
@@ -0,0 +1,118 @@
+package annotations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+const (
+	createConceptIndexTable = `CREATE TABLE IF NOT EXISTS concept_content (
+		concept_uuid TEXT NOT NULL,
+		content_uuid TEXT NOT NULL,
+		PRIMARY KEY (concept_uuid, content_uuid)
+	)`
+	createConceptIndexContentIndex = `CREATE INDEX IF NOT EXISTS concept_content_by_content ON concept_content (content_uuid)`
+	deleteContentConcepts          = `DELETE FROM concept_content WHERE content_uuid = ?`
+	insertContentConcept           = `INSERT INTO concept_content (concept_uuid, content_uuid) VALUES (?, ?)`
+	selectConceptContent           = `SELECT content_uuid FROM concept_content WHERE concept_uuid = ? ORDER BY content_uuid`
+	deleteAllConceptContent        = `DELETE FROM concept_content`
+	// concept_index_complete holds a single row once the index has been completely rebuilt.
+	createConceptIndexCompleteTable = `CREATE TABLE IF NOT EXISTS concept_index_complete (
+		id INTEGER PRIMARY KEY CHECK (id = 1)
+	)`
+	insertConceptIndexComplete = `INSERT OR IGNORE INTO concept_index_complete (id) VALUES (1)`
+	selectConceptIndexComplete = `SELECT COUNT(*) FROM concept_index_complete`
+	deleteConceptIndexComplete = `DELETE FROM concept_index_complete`
+)
+
+type sqliteConceptIndex struct {
+	path string
+	db   *sql.DB
+}
+
+// NewSQLiteConceptIndex returns a ConceptIndex kept in an embedded SQLite database at the given path.
+// The index, and whether it is complete, persist across restarts.
+func NewSQLiteConceptIndex(path string) (ConceptIndex, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	// SQLite allows a single writer at a time
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{createConceptIndexTable, createConceptIndexContentIndex, createConceptIndexCompleteTable} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to create concept index table: %w", err)
+		}
+	}
+	return &sqliteConceptIndex{path: path, db: db}, nil
+}
+
+func (idx *sqliteConceptIndex) Update(ctx context.Context, contentUUID string, annotations []Annotation) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, deleteContentConcepts, contentUUID); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, conceptUUID := range referencedConcepts(annotations) {
+		if _, err := tx.ExecContext(ctx, insertContentConcept, conceptUUID, contentUUID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (idx *sqliteConceptIndex) ContentUUIDs(ctx context.Context, conceptUUID string) ([]string, error) {
+	rows, err := idx.db.QueryContext(ctx, selectConceptContent, conceptUUID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contentUUIDs := []string{}
+	for rows.Next() {
+		var contentUUID string
+		if err := rows.Scan(&contentUUID); err != nil {
+			return nil, err
+		}
+		contentUUIDs = append(contentUUIDs, contentUUID)
+	}
+	return contentUUIDs, rows.Err()
+}
+
+func (idx *sqliteConceptIndex) Reset(ctx context.Context) error {
+	tx, err := idx.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{deleteConceptIndexComplete, deleteAllConceptContent} {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (idx *sqliteConceptIndex) MarkComplete(ctx context.Context) error {
+	_, err := idx.db.ExecContext(ctx, insertConceptIndexComplete)
+	return err
+}
+
+func (idx *sqliteConceptIndex) Complete(ctx context.Context) (bool, error) {
+	var rows int
+	if err := idx.db.QueryRowContext(ctx, selectConceptIndexComplete).Scan(&rows); err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (idx *sqliteConceptIndex) Endpoint() string {
+	return "sqlite://" + idx.path
+}
@@ -0,0 +1,134 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	indexedConceptUUID = "0a619d71-9af5-3755-90dd-f789b686c67a"
+	otherContentUUID   = "8df16ae8-0dfd-4859-a5ff-eeb9644bed35"
+)
+
+func TestInMemoryConceptIndex(t *testing.T) {
+	testConceptIndex(t, NewInMemoryConceptIndex())
+}
+
+func TestSQLiteConceptIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "concept-index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "concept-index.db")
+	idx, err := NewSQLiteConceptIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testConceptIndex(t, idx)
+	assert.Equal(t, "sqlite://"+path, idx.Endpoint())
+
+	assert.NoError(t, idx.MarkComplete(context.Background()))
+	reopened, err := NewSQLiteConceptIndex(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	complete, err := reopened.Complete(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, complete, "a complete index stays complete across restarts")
+}
+
+// testConceptIndex checks that a ConceptIndex implementation keeps track of the drafts referencing each concept.
+func testConceptIndex(t *testing.T, idx ConceptIndex) {
+	ctx := context.Background()
+
+	contentUUIDs, err := idx.ContentUUIDs(ctx, indexedConceptUUID)
+	assert.NoError(t, err)
+	assert.Empty(t, contentUUIDs)
+	complete, err := idx.Complete(ctx)
+	assert.NoError(t, err)
+	assert.False(t, complete, "an index is incomplete until it is rebuilt")
+
+	assert.NoError(t, idx.Update(ctx, testContentUUID, []Annotation{
+		{Predicate: "http://www.ft.com/ontology/annotation/mentions", ConceptId: "http://www.ft.com/thing/" + indexedConceptUUID},
+		{Predicate: "http://www.ft.com/ontology/annotation/about", ConceptId: "http://api.ft.com/things/" + indexedConceptUUID},
+		{Predicate: "http://www.ft.com/ontology/annotation/about", ConceptId: "http://www.ft.com/thing/838b3fbe-efbc-3cfe-b5c0-d38c046492a4"},
+	}))
+	assert.NoError(t, idx.Update(ctx, otherContentUUID, []Annotation{
+		{Predicate: "http://www.ft.com/ontology/annotation/about", ConceptId: "http://www.ft.com/thing/" + indexedConceptUUID},
+	}))
+
+	contentUUIDs, err = idx.ContentUUIDs(ctx, indexedConceptUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{otherContentUUID, testContentUUID}, contentUUIDs)
+
+	assert.NoError(t, idx.Update(ctx, testContentUUID, []Annotation{
+		{Predicate: "http://www.ft.com/ontology/annotation/about", ConceptId: "http://www.ft.com/thing/838b3fbe-efbc-3cfe-b5c0-d38c046492a4"},
+	}))
+	assert.NoError(t, idx.Update(ctx, otherContentUUID, nil))
+
+	contentUUIDs, err = idx.ContentUUIDs(ctx, indexedConceptUUID)
+	assert.NoError(t, err)
+	assert.Empty(t, contentUUIDs)
+
+	contentUUIDs, err = idx.ContentUUIDs(ctx, "838b3fbe-efbc-3cfe-b5c0-d38c046492a4")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{testContentUUID}, contentUUIDs)
+
+	assert.NoError(t, idx.MarkComplete(ctx))
+	complete, err = idx.Complete(ctx)
+	assert.NoError(t, err)
+	assert.True(t, complete)
+
+	assert.NoError(t, idx.Reset(ctx))
+	contentUUIDs, err = idx.ContentUUIDs(ctx, "838b3fbe-efbc-3cfe-b5c0-d38c046492a4")
+	assert.NoError(t, err)
+	assert.Empty(t, contentUUIDs)
+	complete, err = idx.Complete(ctx)
+	assert.NoError(t, err)
+	assert.False(t, complete, "a reset index is incomplete")
+
+	assert.NotEmpty(t, idx.Endpoint())
+}
+
+func TestRebuildConceptIndex(t *testing.T) {
+	ctx := context.Background()
+	rw := NewInMemoryRW()
+	_, err := rw.Write(ctx, testContentUUID, &Annotations{Annotations: []Annotation{
+		{Predicate: "http://www.ft.com/ontology/annotation/mentions", ConceptId: "http://www.ft.com/thing/" + indexedConceptUUID},
+	}}, "")
+	assert.NoError(t, err)
+
+	idx := NewInMemoryConceptIndex()
+	// a stale entry for a content without a draft
+	assert.NoError(t, idx.Update(ctx, otherContentUUID, []Annotation{
+		{Predicate: "http://www.ft.com/ontology/annotation/about", ConceptId: "http://www.ft.com/thing/" + indexedConceptUUID},
+	}))
+
+	indexed, err := RebuildConceptIndex(ctx, idx, rw, []string{testContentUUID})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+	contentUUIDs, _ := idx.ContentUUIDs(ctx, indexedConceptUUID)
+	assert.Equal(t, []string{otherContentUUID, testContentUUID}, contentUUIDs)
+	complete, _ := idx.Complete(ctx)
+	assert.False(t, complete, "indexing some contents does not complete the index")
+
+	indexed, err = RebuildConceptIndex(ctx, idx, rw, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, indexed)
+	contentUUIDs, _ = idx.ContentUUIDs(ctx, indexedConceptUUID)
+	assert.Equal(t, []string{testContentUUID}, contentUUIDs)
+	complete, _ = idx.Complete(ctx)
+	assert.True(t, complete)
+
+	_, err = RebuildConceptIndex(ctx, idx, NewRW(http.DefaultClient, "http://localhost"), nil)
+	assert.True(t, errors.Is(err, ErrDraftsNotListable))
+}
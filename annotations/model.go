@@ -1,6 +1,10 @@
 package annotations
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/Financial-Times/draft-annotations-api/concept"
+)
 
 type Annotations struct {
 	Annotations []Annotation `json:"annotations"`
@@ -13,6 +17,19 @@ type Annotation struct {
 	Type       string `json:"type,omitempty"`
 	PrefLabel  string `json:"prefLabel,omitempty"`
 	IsFTAuthor bool   `json:"isFTAuthor,omitempty"`
+	MergedFrom string `json:"mergedFrom,omitempty"`
+	Lifecycle  string `json:"lifecycle,omitempty"`
+	*ConceptExpansion
+}
+
+// ConceptExpansion is the concept data beyond the label and type, only returned on demand.
+// It is nil when UPP knows none of it.
+type ConceptExpansion struct {
+	Aliases         []string                 `json:"aliases,omitempty"`
+	DescriptionXML  string                   `json:"descriptionXML,omitempty"`
+	ImageURL        string                   `json:"imageUrl,omitempty"`
+	BroaderConcepts []concept.BroaderConcept `json:"broaderConcepts,omitempty"`
+	IsDeprecated    bool                     `json:"isDeprecated,omitempty"`
 }
 
 func userAgent(req *http.Request) {
@@ -0,0 +1,140 @@
+package annotations
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	allLifecycles      = "all"
+	editableLifecycles = "editable"
+)
+
+// PublishedAPI gets the published annotations of content from UPP.
+type PublishedAPI interface {
+	GetAll(ctx context.Context, contentUUID string) ([]Annotation, error)
+	GetEditable(ctx context.Context, contentUUID string) ([]Annotation, error)
+	GetByLifecycles(ctx context.Context, contentUUID string, lifecycles []string) ([]Annotation, error)
+	Endpoint() string
+	GTG() error
+}
+
+type cachedPublished struct {
+	annotations []Annotation
+	expires     time.Time
+}
+
+// CachedPublishedAPI caches the published annotations returned by the PublishedAPI it wraps for a TTL,
+// by content and set of lifecycles. Errors are not cached.
+type CachedPublishedAPI struct {
+	PublishedAPI
+
+	sync.Mutex
+	ttl     time.Duration
+	entries map[string]cachedPublished
+	now     func() time.Time
+	// invalidations counts the calls to Invalidate, so that annotations fetched meanwhile are not cached.
+	invalidations uint64
+}
+
+// NewCachedPublishedAPI wraps api with a cache keeping the published annotations for ttl.
+func NewCachedPublishedAPI(api PublishedAPI, ttl time.Duration) *CachedPublishedAPI {
+	return &CachedPublishedAPI{PublishedAPI: api, ttl: ttl, entries: make(map[string]cachedPublished), now: time.Now}
+}
+
+// GetAll retrieves the list of published annotations for given contentUUID, from the cache if possible.
+func (c *CachedPublishedAPI) GetAll(ctx context.Context, contentUUID string) ([]Annotation, error) {
+	list, _, err := c.GetAllWithCacheStatus(ctx, contentUUID)
+	return list, err
+}
+
+// GetEditable retrieves the list of editable published annotations for given contentUUID, from the cache if possible.
+func (c *CachedPublishedAPI) GetEditable(ctx context.Context, contentUUID string) ([]Annotation, error) {
+	list, _, err := c.GetEditableWithCacheStatus(ctx, contentUUID)
+	return list, err
+}
+
+// GetByLifecycles retrieves the list of published annotations of the given lifecycles for given contentUUID,
+// from the cache if possible.
+func (c *CachedPublishedAPI) GetByLifecycles(ctx context.Context, contentUUID string, lifecycles []string) ([]Annotation, error) {
+	list, _, err := c.GetByLifecyclesWithCacheStatus(ctx, contentUUID, lifecycles)
+	return list, err
+}
+
+// GetAllWithCacheStatus is GetAll, also telling whether the annotations come from the cache.
+func (c *CachedPublishedAPI) GetAllWithCacheStatus(ctx context.Context, contentUUID string) ([]Annotation, bool, error) {
+	return c.get(ctx, contentUUID, allLifecycles, c.PublishedAPI.GetAll)
+}
+
+// GetEditableWithCacheStatus is GetEditable, also telling whether the annotations come from the cache.
+func (c *CachedPublishedAPI) GetEditableWithCacheStatus(ctx context.Context, contentUUID string) ([]Annotation, bool, error) {
+	return c.get(ctx, contentUUID, editableLifecycles, c.PublishedAPI.GetEditable)
+}
+
+// GetByLifecyclesWithCacheStatus is GetByLifecycles, also telling whether the annotations come from the cache.
+func (c *CachedPublishedAPI) GetByLifecyclesWithCacheStatus(ctx context.Context, contentUUID string, lifecycles []string) ([]Annotation, bool, error) {
+	sorted := append([]string(nil), lifecycles...)
+	sort.Strings(sorted)
+	return c.get(ctx, contentUUID, "lifecycles:"+strings.Join(sorted, ","), func(ctx context.Context, contentUUID string) ([]Annotation, error) {
+		return c.PublishedAPI.GetByLifecycles(ctx, contentUUID, lifecycles)
+	})
+}
+
+// Invalidate forgets the published annotations of the content, e.g. because it has been republished.
+func (c *CachedPublishedAPI) Invalidate(contentUUID string) {
+	c.Lock()
+	defer c.Unlock()
+	prefix := publishedKey(contentUUID, "")
+	for k := range c.entries {
+		if strings.HasPrefix(k, prefix) {
+			delete(c.entries, k)
+		}
+	}
+	c.invalidations++
+}
+
+func (c *CachedPublishedAPI) get(ctx context.Context, contentUUID string, lifecycles string, get func(context.Context, string) ([]Annotation, error)) ([]Annotation, bool, error) {
+	key := publishedKey(contentUUID, lifecycles)
+
+	c.Lock()
+	e, found := c.entries[key]
+	invalidations := c.invalidations
+	c.Unlock()
+	if found && c.now().Before(e.expires) {
+		return copyAnnotations(e.annotations), true, nil
+	}
+
+	list, err := get(ctx, contentUUID)
+	if err != nil {
+		// The annotations of a partial failure are passed on, but not cached.
+		return list, false, err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.invalidations != invalidations {
+		return list, false, nil
+	}
+	now := c.now()
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = cachedPublished{annotations: copyAnnotations(list), expires: now.Add(c.ttl)}
+	return list, false, nil
+}
+
+func publishedKey(contentUUID string, lifecycles string) string {
+	return contentUUID + "/" + lifecycles
+}
+
+// copyAnnotations copies the list, as the callers are free to modify the annotations they get.
+func copyAnnotations(list []Annotation) []Annotation {
+	copied := make([]Annotation, len(list))
+	copy(copied, list)
+	return copied
+}
@@ -0,0 +1,136 @@
+package annotations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type publishedAPIStub struct {
+	calls       map[string]int
+	annotations []Annotation
+	err         error
+}
+
+func (s *publishedAPIStub) GetAll(_ context.Context, contentUUID string) ([]Annotation, error) {
+	s.calls["all"]++
+	return s.annotations, s.err
+}
+
+func (s *publishedAPIStub) GetEditable(_ context.Context, contentUUID string) ([]Annotation, error) {
+	s.calls["editable"]++
+	return s.annotations, s.err
+}
+
+func (s *publishedAPIStub) GetByLifecycles(_ context.Context, contentUUID string, lifecycles []string) ([]Annotation, error) {
+	s.calls[strings.Join(lifecycles, ",")]++
+	return s.annotations, s.err
+}
+
+func (s *publishedAPIStub) Endpoint() string {
+	return "http://annotations"
+}
+
+func (s *publishedAPIStub) GTG() error {
+	return nil
+}
+
+var testPublished = []Annotation{
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/mentions",
+		ConceptId: "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+	},
+}
+
+func TestCachedPublishedAPI(t *testing.T) {
+	api := &publishedAPIStub{calls: map[string]int{}, annotations: testPublished}
+	c := NewCachedPublishedAPI(api, time.Minute)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	list, cached, err := c.GetAllWithCacheStatus(context.Background(), "uuid")
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, testPublished, list)
+
+	list, cached, err = c.GetAllWithCacheStatus(context.Background(), "uuid")
+	assert.NoError(t, err)
+	assert.True(t, cached)
+	assert.Equal(t, testPublished, list)
+	assert.Equal(t, 1, api.calls["all"])
+
+	list[0].ConceptId = "modified by the caller"
+	list, err = c.GetAll(context.Background(), "uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, testPublished, list, "the cached annotations are copied")
+
+	_, cached, err = c.GetEditableWithCacheStatus(context.Background(), "uuid")
+	assert.NoError(t, err)
+	assert.False(t, cached, "the lifecycles are cached separately")
+	_, err = c.GetEditable(context.Background(), "uuid")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, api.calls["editable"])
+
+	_, cached, _ = c.GetAllWithCacheStatus(context.Background(), "other-uuid")
+	assert.False(t, cached)
+
+	now = now.Add(time.Minute)
+	_, cached, _ = c.GetAllWithCacheStatus(context.Background(), "uuid")
+	assert.False(t, cached, "the cached annotations expire")
+	assert.Equal(t, 3, api.calls["all"])
+
+	assert.Equal(t, "http://annotations", c.Endpoint())
+}
+
+func TestCachedPublishedAPIInvalidate(t *testing.T) {
+	api := &publishedAPIStub{calls: map[string]int{}, annotations: testPublished}
+	c := NewCachedPublishedAPI(api, time.Minute)
+
+	_, _ = c.GetAll(context.Background(), "uuid")
+	_, _ = c.GetEditable(context.Background(), "uuid")
+	_, _ = c.GetByLifecycles(context.Background(), "uuid", []string{"v2"})
+	c.Invalidate("uuid")
+
+	_, cached, _ := c.GetAllWithCacheStatus(context.Background(), "uuid")
+	assert.False(t, cached)
+	_, cached, _ = c.GetEditableWithCacheStatus(context.Background(), "uuid")
+	assert.False(t, cached)
+	_, cached, _ = c.GetByLifecyclesWithCacheStatus(context.Background(), "uuid", []string{"v2"})
+	assert.False(t, cached)
+}
+
+func TestCachedPublishedAPIByLifecycles(t *testing.T) {
+	api := &publishedAPIStub{calls: map[string]int{}, annotations: testPublished}
+	c := NewCachedPublishedAPI(api, time.Minute)
+
+	list, cached, err := c.GetByLifecyclesWithCacheStatus(context.Background(), "uuid", []string{"v2", "pac"})
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, testPublished, list)
+
+	_, cached, _ = c.GetByLifecyclesWithCacheStatus(context.Background(), "uuid", []string{"pac", "v2"})
+	assert.True(t, cached, "the set of lifecycles is cached whatever their order")
+	assert.Equal(t, 1, api.calls["v2,pac"])
+
+	_, cached, _ = c.GetByLifecyclesWithCacheStatus(context.Background(), "uuid", []string{"pac"})
+	assert.False(t, cached)
+}
+
+func TestCachedPublishedAPIErrorsAreNotCached(t *testing.T) {
+	api := &publishedAPIStub{calls: map[string]int{}, err: errors.New("UPP unavailable")}
+	c := NewCachedPublishedAPI(api, time.Minute)
+
+	_, err := c.GetAll(context.Background(), "uuid")
+	assert.Error(t, err)
+
+	api.err = nil
+	api.annotations = testPublished
+	list, cached, err := c.GetAllWithCacheStatus(context.Background(), "uuid")
+	assert.NoError(t, err)
+	assert.False(t, cached)
+	assert.Equal(t, testPublished, list)
+}
@@ -0,0 +1,81 @@
+package concept
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LastKnownCache is a ReadAPI remembering the last data returned for each concept by the API it wraps,
+// so that it can still be looked up when that API is unavailable.
+// The least recently fetched concepts are evicted once the cache holds size concepts.
+type LastKnownCache struct {
+	ReadAPI
+
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+type lastKnownEntry struct {
+	id      string
+	concept Concept
+}
+
+// NewLastKnownCache wraps api with a cache of at most size concepts.
+func NewLastKnownCache(api ReadAPI, size int) *LastKnownCache {
+	return &LastKnownCache{
+		ReadAPI: api,
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// GetConceptsByIDs gets the concepts from the wrapped API and remembers them.
+func (c *LastKnownCache) GetConceptsByIDs(ctx context.Context, ids []string) (map[string]Concept, error) {
+	concepts, err := c.ReadAPI.GetConceptsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, concept := range concepts {
+		c.store(id, concept)
+	}
+	return concepts, nil
+}
+
+// Lookup returns the last known data of the given concepts, without calling the wrapped API.
+// Concepts never fetched, or evicted, are missing from the result.
+func (c *LastKnownCache) Lookup(ids []string) map[string]Concept {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	concepts := make(map[string]Concept)
+	for _, id := range ids {
+		if e, found := c.entries[id]; found {
+			concepts[id] = e.Value.(*lastKnownEntry).concept
+		}
+	}
+	return concepts
+}
+
+func (c *LastKnownCache) store(id string, concept Concept) {
+	if e, found := c.entries[id]; found {
+		e.Value.(*lastKnownEntry).concept = concept
+		c.order.MoveToFront(e)
+		return
+	}
+	if c.size <= 0 {
+		return
+	}
+	c.entries[id] = c.order.PushFront(&lastKnownEntry{id: id, concept: concept})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lastKnownEntry).id)
+	}
+}
@@ -0,0 +1,92 @@
+package concept
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type readAPIStub struct {
+	concepts map[string]Concept
+	err      error
+}
+
+func (s *readAPIStub) GetConceptsByIDs(_ context.Context, ids []string) (map[string]Concept, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	result := make(map[string]Concept)
+	for _, id := range ids {
+		if c, found := s.concepts[id]; found {
+			result[id] = c
+		}
+	}
+	return result, nil
+}
+
+func (s *readAPIStub) Endpoint() string {
+	return "http://concepts"
+}
+
+func (s *readAPIStub) GTG() error {
+	return s.err
+}
+
+func TestLastKnownCacheLookup(t *testing.T) {
+	concepts := generateConcepts(3)
+	ids := extractIDs(concepts)
+	api := &readAPIStub{concepts: concepts}
+	cache := NewLastKnownCache(api, 10)
+
+	assert.Empty(t, cache.Lookup(ids))
+
+	actual, err := cache.GetConceptsByIDs(context.Background(), ids)
+	assert.NoError(t, err)
+	assert.Equal(t, concepts, actual)
+
+	api.err = errors.New("concepts API unavailable")
+	_, err = cache.GetConceptsByIDs(context.Background(), ids)
+	assert.Error(t, err)
+
+	assert.Equal(t, concepts, cache.Lookup(append(ids, "unknown")))
+	assert.Equal(t, "http://concepts", cache.Endpoint())
+}
+
+func TestLastKnownCacheUpdatesConcepts(t *testing.T) {
+	concepts := generateConcepts(1)
+	ids := extractIDs(concepts)
+	api := &readAPIStub{concepts: concepts}
+	cache := NewLastKnownCache(api, 10)
+
+	_, err := cache.GetConceptsByIDs(context.Background(), ids)
+	assert.NoError(t, err)
+
+	updated := concepts[ids[0]]
+	updated.PrefLabel = "Updated"
+	api.concepts[ids[0]] = updated
+	_, err = cache.GetConceptsByIDs(context.Background(), ids)
+	assert.NoError(t, err)
+
+	assert.Equal(t, map[string]Concept{ids[0]: updated}, cache.Lookup(ids))
+}
+
+func TestLastKnownCacheEvictsLeastRecentlyFetched(t *testing.T) {
+	concepts := generateConcepts(3)
+	ids := extractIDs(concepts)
+	cache := NewLastKnownCache(&readAPIStub{concepts: concepts}, 2)
+
+	for _, id := range ids {
+		_, err := cache.GetConceptsByIDs(context.Background(), []string{id})
+		assert.NoError(t, err)
+	}
+	_, err := cache.GetConceptsByIDs(context.Background(), []string{ids[1]})
+	assert.NoError(t, err)
+
+	known := cache.Lookup(ids)
+	assert.Len(t, known, 2)
+	assert.NotContains(t, known, ids[0])
+	assert.Contains(t, known, ids[1])
+	assert.Contains(t, known, ids[2])
+}
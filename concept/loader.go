@@ -0,0 +1,154 @@
+package concept
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Loader is a ReadAPI collecting the concept IDs requested by concurrent callers during a short window,
+// to get them from the API it wraps in combined batches.
+// A batch is sent right away when no other batch is being loaded, otherwise when the window of its first ID
+// has elapsed, or as soon as it holds batchSize IDs. Each caller receives the concepts it asked for only.
+type Loader struct {
+	ReadAPI
+
+	window    time.Duration
+	batchSize int
+
+	mu      sync.Mutex
+	pending *loaderBatch
+	loading int
+}
+
+type loaderBatch struct {
+	tid      string
+	ids      []string
+	included map[string]struct{}
+	done     chan struct{}
+	concepts map[string]Concept
+	err      error
+
+	// deadline is the latest deadline of the callers waiting for the batch, unless one of them has none.
+	deadline  time.Time
+	unbounded bool
+}
+
+// NewLoader wraps api with a Loader sending the batches after window, or once they hold batchSize IDs.
+func NewLoader(api ReadAPI, window time.Duration, batchSize int) *Loader {
+	return &Loader{ReadAPI: api, window: window, batchSize: batchSize}
+}
+
+func (l *Loader) GetConceptsByIDs(ctx context.Context, conceptIDs []string) (map[string]Concept, error) {
+	tid, err := tidUtils.GetTransactionIDFromContext(ctx)
+	if err != nil {
+		tid = tidUtils.NewTransactionID()
+	}
+
+	batches := l.add(ctx, tid, conceptIDs)
+
+	result := make(map[string]Concept)
+	for _, b := range batches {
+		select {
+		case <-b.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+		for _, id := range conceptIDs {
+			if c, found := b.concepts[id]; found {
+				result[id] = c
+			}
+		}
+	}
+	return result, nil
+}
+
+// add adds the IDs to the pending batches and returns the batches the caller has to wait for.
+// The last batch is sent right away if no batches but those of the caller are being loaded.
+func (l *Loader) add(ctx context.Context, tid string, conceptIDs []string) []*loaderBatch {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var batches []*loaderBatch
+	sent := 0
+	for _, id := range conceptIDs {
+		if l.pending == nil {
+			l.pending = &loaderBatch{tid: tid, included: make(map[string]struct{}), done: make(chan struct{})}
+			b := l.pending
+			time.AfterFunc(l.window, func() { l.flush(b) })
+		}
+		b := l.pending
+		if len(batches) == 0 || batches[len(batches)-1] != b {
+			batches = append(batches, b)
+			b.extendDeadline(ctx)
+		}
+		if _, found := b.included[id]; found {
+			continue
+		}
+		b.included[id] = struct{}{}
+		b.ids = append(b.ids, id)
+		if len(b.ids) >= l.batchSize {
+			l.send(b)
+			sent++
+		}
+	}
+	if l.pending != nil && len(batches) > 0 && batches[len(batches)-1] == l.pending && l.loading == sent {
+		l.send(l.pending)
+	}
+	return batches
+}
+
+// extendDeadline makes the batch wait for the deadline of the caller, if it is later than those of the others.
+func (b *loaderBatch) extendDeadline(ctx context.Context) {
+	deadline, ok := ctx.Deadline()
+	switch {
+	case !ok:
+		b.unbounded = true
+	case deadline.After(b.deadline):
+		b.deadline = deadline
+	}
+}
+
+// send loads the batch, which must be the pending one, in the background. It is called with the lock held.
+func (l *Loader) send(b *loaderBatch) {
+	l.pending = nil
+	l.loading++
+	go l.load(b)
+}
+
+// flush sends the batch when its window has elapsed, unless it has been sent already for being full.
+func (l *Loader) flush(b *loaderBatch) {
+	l.mu.Lock()
+	if l.pending != b {
+		l.mu.Unlock()
+		return
+	}
+	l.send(b)
+	l.mu.Unlock()
+}
+
+// load gets the concepts of the batch. It is not cancelled with the context of any caller,
+// as any of them may give up without the others doing so, but it is limited to the latest of their deadlines.
+func (l *Loader) load(b *loaderBatch) {
+	ctx := tidUtils.TransactionAwareContext(context.Background(), b.tid)
+	cancel := func() {}
+	if !b.unbounded {
+		ctx, cancel = context.WithDeadline(ctx, b.deadline)
+	}
+	b.concepts, b.err = l.ReadAPI.GetConceptsByIDs(ctx, b.ids)
+	cancel()
+	if b.err == nil {
+		log.WithField(tidUtils.TransactionIDKey, b.tid).WithField("concepts", len(b.ids)).Debug("Concepts batch loaded")
+	}
+
+	l.mu.Lock()
+	l.loading--
+	l.mu.Unlock()
+	close(b.done)
+}
@@ -0,0 +1,184 @@
+package concept
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingReadAPI struct {
+	readAPIStub
+	mu        sync.Mutex
+	batches   [][]string
+	deadlines []time.Time
+	// release, when set, holds the batches until it is closed.
+	release chan struct{}
+}
+
+func (r *recordingReadAPI) GetConceptsByIDs(ctx context.Context, ids []string) (map[string]Concept, error) {
+	r.mu.Lock()
+	r.batches = append(r.batches, ids)
+	deadline, _ := ctx.Deadline()
+	r.deadlines = append(r.deadlines, deadline)
+	r.mu.Unlock()
+	if r.release != nil {
+		<-r.release
+	}
+	return r.readAPIStub.GetConceptsByIDs(ctx, ids)
+}
+
+func (r *recordingReadAPI) sent() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.batches)
+}
+
+func waitForBatches(t *testing.T, api *recordingReadAPI, n int) {
+	for start := time.Now(); api.sent() < n; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatalf("expected %d batches, got %d", n, api.sent())
+		}
+	}
+}
+
+func TestLoaderCombinesConcurrentRequests(t *testing.T) {
+	concepts := generateConcepts(7)
+	ids := extractIDs(concepts)
+	sort.Strings(ids)
+	api := &recordingReadAPI{readAPIStub: readAPIStub{concepts: concepts}, release: make(chan struct{})}
+	loader := NewLoader(api, 50*time.Millisecond, 30)
+
+	loading := make(chan struct{})
+	go func() {
+		_, err := loader.GetConceptsByIDs(context.Background(), ids[6:])
+		assert.NoError(t, err)
+		close(loading)
+	}()
+	waitForBatches(t, api, 1)
+
+	requests := [][]string{
+		{ids[0], ids[1]},
+		{ids[1], ids[2], ids[3]},
+		{ids[4], ids[5], "unknown"},
+	}
+	results := make([]map[string]Concept, len(requests))
+	var wg sync.WaitGroup
+	for i, request := range requests {
+		wg.Add(1)
+		go func(i int, request []string) {
+			defer wg.Done()
+			ctx := tidUtils.TransactionAwareContext(context.Background(), tidUtils.NewTransactionID())
+			var err error
+			results[i], err = loader.GetConceptsByIDs(ctx, request)
+			assert.NoError(t, err)
+		}(i, request)
+	}
+	waitForBatches(t, api, 2)
+	close(api.release)
+	wg.Wait()
+	<-loading
+
+	assert.Len(t, api.batches, 2)
+	assert.Equal(t, ids[6:], api.batches[0], "the first batch is sent right away")
+	assert.ElementsMatch(t, append(ids[:6], "unknown"), api.batches[1], "the requests made while it is loaded are combined")
+	for i, request := range requests {
+		expected := make(map[string]Concept)
+		for _, id := range request {
+			if c, found := concepts[id]; found {
+				expected[id] = c
+			}
+		}
+		assert.Equal(t, expected, results[i])
+	}
+}
+
+func TestLoaderSendsFullBatches(t *testing.T) {
+	concepts := generateConcepts(5)
+	ids := extractIDs(concepts)
+	api := &recordingReadAPI{readAPIStub: readAPIStub{concepts: concepts}}
+	loader := NewLoader(api, time.Hour, 2)
+
+	actual, err := loader.GetConceptsByIDs(context.Background(), ids[:4])
+	assert.NoError(t, err)
+	assert.Equal(t, 4, len(actual))
+	assert.Len(t, api.batches, 2, "full batches are sent without waiting for the window")
+	for _, batch := range api.batches {
+		assert.Len(t, batch, 2)
+	}
+}
+
+func TestLoaderSendsRightAwayWhenIdle(t *testing.T) {
+	concepts := generateConcepts(3)
+	ids := extractIDs(concepts)
+	api := &recordingReadAPI{readAPIStub: readAPIStub{concepts: concepts}}
+	loader := NewLoader(api, time.Hour, 30)
+
+	for _, id := range ids {
+		actual, err := loader.GetConceptsByIDs(context.Background(), []string{id})
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]Concept{id: concepts[id]}, actual)
+	}
+	assert.Len(t, api.batches, 3)
+}
+
+func TestLoaderKeepsTheLatestCallerDeadline(t *testing.T) {
+	api := &recordingReadAPI{readAPIStub: readAPIStub{concepts: generateConcepts(1)}, release: make(chan struct{})}
+	loader := NewLoader(api, 50*time.Millisecond, 30)
+
+	go loader.GetConceptsByIDs(context.Background(), []string{"first"})
+	waitForBatches(t, api, 1)
+
+	deadline := time.Now().Add(time.Minute)
+	var wg sync.WaitGroup
+	for _, d := range []time.Time{deadline.Add(-time.Second), deadline} {
+		wg.Add(1)
+		go func(d time.Time) {
+			defer wg.Done()
+			ctx, cancel := context.WithDeadline(context.Background(), d)
+			defer cancel()
+			_, err := loader.GetConceptsByIDs(ctx, []string{"id"})
+			assert.NoError(t, err)
+		}(d)
+	}
+	waitForBatches(t, api, 2)
+	close(api.release)
+	wg.Wait()
+
+	assert.True(t, api.deadlines[0].IsZero(), "the batch of a caller without deadline has none")
+	assert.Equal(t, deadline, api.deadlines[1])
+}
+
+func TestLoaderError(t *testing.T) {
+	api := &recordingReadAPI{readAPIStub: readAPIStub{err: errors.New("concepts API unavailable")}}
+	loader := NewLoader(api, time.Millisecond, 30)
+
+	_, err := loader.GetConceptsByIDs(context.Background(), []string{"id"})
+	assert.EqualError(t, err, "concepts API unavailable")
+}
+
+func TestLoaderCallerContext(t *testing.T) {
+	api := &recordingReadAPI{readAPIStub: readAPIStub{concepts: generateConcepts(1)}, release: make(chan struct{})}
+	defer close(api.release)
+	loader := NewLoader(api, time.Hour, 30)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := loader.GetConceptsByIDs(ctx, []string{"id"})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestLoaderNoConcepts(t *testing.T) {
+	api := &recordingReadAPI{}
+	loader := NewLoader(api, time.Hour, 30)
+
+	actual, err := loader.GetConceptsByIDs(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+	assert.Empty(t, api.batches)
+}
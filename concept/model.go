@@ -5,11 +5,30 @@ type SearchResult struct {
 	Concepts map[string]Concept `json:"concepts"`
 }
 
-// Concept models the concept data returned from the UPP concepts API
+// SearchResponse models the data returned from the concept search backend, the concepts matching the search
+type SearchResponse struct {
+	Concepts []Concept `json:"concepts"`
+}
+
+// Concept models the concept data returned from the UPP concepts API.
+// The aliases, description, image, broader concepts and deprecation status are only set when UPP knows them.
 type Concept struct {
-	ID         string `json:"id"`
-	ApiUrl     string `json:"apiUrl,omitempty"`
-	Type       string `json:"type,omitempty"`
-	PrefLabel  string `json:"prefLabel,omitempty"`
-	IsFTAuthor bool   `json:"isFTAuthor,omitempty"`
+	ID              string           `json:"id"`
+	ApiUrl          string           `json:"apiUrl,omitempty"`
+	Type            string           `json:"type,omitempty"`
+	PrefLabel       string           `json:"prefLabel,omitempty"`
+	IsFTAuthor      bool             `json:"isFTAuthor,omitempty"`
+	Aliases         []string         `json:"aliases,omitempty"`
+	DescriptionXML  string           `json:"descriptionXML,omitempty"`
+	ImageURL        string           `json:"imageUrl,omitempty"`
+	BroaderConcepts []BroaderConcept `json:"broaderConcepts,omitempty"`
+	IsDeprecated    bool             `json:"isDeprecated,omitempty"`
+}
+
+// BroaderConcept models a concept the concept is narrower than, e.g. the parent organisation of a company
+type BroaderConcept struct {
+	ID        string `json:"id"`
+	ApiUrl    string `json:"apiUrl,omitempty"`
+	Type      string `json:"type,omitempty"`
+	PrefLabel string `json:"prefLabel,omitempty"`
 }
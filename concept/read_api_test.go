@@ -58,6 +58,41 @@ func TestGetConceptsByIDsMultipleBatches(t *testing.T) {
 	assert.Equal(t, expectedConcepts, actualConcepts)
 }
 
+func TestGetConceptsByIDsDecodesConceptDetails(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"concepts":{"6b43a14b-a5e0-3b63-a428-aa55def05fcb":{
+			"id":"http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+			"apiUrl":"http://api.ft.com/things/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+			"type":"http://www.ft.com/ontology/Section",
+			"prefLabel":"FT Confidential Research",
+			"aliases":["FTCR","Confidential Research"],
+			"descriptionXML":"<p>Independent research on China and South East Asia.</p>",
+			"imageUrl":"http://images.ft.com/ftcr.png",
+			"broaderConcepts":[{"id":"http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54","type":"http://www.ft.com/ontology/product/Brand","prefLabel":"Financial Times"}],
+			"isDeprecated":true,
+			"scopeNote":"not captured"
+		}}}`)
+	}))
+	defer s.Close()
+
+	csAPI := NewReadAPI(testClient, s.URL, "apiKey", 10)
+	concepts, err := csAPI.GetConceptsByIDs(context.Background(), []string{"6b43a14b-a5e0-3b63-a428-aa55def05fcb"})
+	assert.NoError(t, err)
+	assert.Equal(t, Concept{
+		ID:             "http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+		ApiUrl:         "http://api.ft.com/things/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+		Type:           "http://www.ft.com/ontology/Section",
+		PrefLabel:      "FT Confidential Research",
+		Aliases:        []string{"FTCR", "Confidential Research"},
+		DescriptionXML: "<p>Independent research on China and South East Asia.</p>",
+		ImageURL:       "http://images.ft.com/ftcr.png",
+		BroaderConcepts: []BroaderConcept{
+			{ID: "http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54", Type: "http://www.ft.com/ontology/product/Brand", PrefLabel: "Financial Times"},
+		},
+		IsDeprecated: true,
+	}, concepts["6b43a14b-a5e0-3b63-a428-aa55def05fcb"])
+}
+
 func TestGetConceptsByIDsMissingTID(t *testing.T) {
 	hook := logTest.NewGlobal()
 	batchSize := 20
@@ -0,0 +1,83 @@
+package concept
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// SearchAPI searches the concepts by label, for typeahead.
+type SearchAPI interface {
+	SearchConcepts(ctx context.Context, query string, types []string) ([]Concept, error)
+	Endpoint() string
+}
+
+// ErrUnexpectedSearchResponse is returned when the concept search backend responds with a non-200 HTTP status code.
+var ErrUnexpectedSearchResponse = errors.New("concept search backend returned a non-200 HTTP status code")
+
+type conceptSearchAPI struct {
+	endpoint   string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewSearchAPI initializes a SearchAPI calling the concept search backend at the given endpoint,
+// with the q query parameter holding the searched text and a type query parameter for each of the searched types.
+func NewSearchAPI(client *http.Client, endpoint string, apiKey string) SearchAPI {
+	return &conceptSearchAPI{endpoint: endpoint, apiKey: apiKey, httpClient: client}
+}
+
+func (search *conceptSearchAPI) SearchConcepts(ctx context.Context, query string, types []string) ([]Concept, error) {
+	tid, err := tidUtils.GetTransactionIDFromContext(ctx)
+	if err != nil {
+		tid = tidUtils.NewTransactionID()
+	}
+	searchLog := log.WithField(tidUtils.TransactionIDKey, tid).WithField("query", query)
+
+	req, err := http.NewRequest("GET", search.endpoint, nil)
+	if err != nil {
+		searchLog.WithError(err).Error("Error in creating the HTTP request to the concept search backend")
+		return nil, err
+	}
+	req.Header.Set(apiKeyHeader, search.apiKey)
+	req.Header.Set(tidUtils.TransactionIDHeader, tid)
+
+	q := url.Values{}
+	q.Set("q", query)
+	for _, t := range types {
+		q.Add("type", t)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := search.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		searchLog.WithError(err).Error("Error making the HTTP request to the concept search backend")
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		err = fmt.Errorf("status %d %s: %w", resp.StatusCode, string(body), ErrUnexpectedSearchResponse)
+		searchLog.WithError(err).Error("Error received from the concept search backend")
+		return nil, err
+	}
+
+	var result SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		searchLog.WithError(err).Error("Error in unmarshalling the HTTP response from the concept search backend")
+		return nil, err
+	}
+	return result.Concepts, nil
+}
+
+func (search *conceptSearchAPI) Endpoint() string {
+	return search.endpoint
+}
@@ -0,0 +1,49 @@
+package concept
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchConcepts(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/concepts", r.URL.Path)
+		assert.Equal(t, "tech", r.URL.Query().Get("q"))
+		assert.Equal(t, []string{"http://www.ft.com/ontology/Topic", "http://www.ft.com/ontology/Location"}, r.URL.Query()["type"])
+		assert.Equal(t, "apiKey", r.Header.Get(apiKeyHeader))
+		assert.Equal(t, "tid_search", r.Header.Get(tidUtils.TransactionIDHeader))
+		_, _ = w.Write([]byte(`{"concepts":[{"id":"http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4","apiUrl":"http://api.ft.com/things/5507ab98-b747-3ebc-b816-11603b9009f4","type":"http://www.ft.com/ontology/Topic","prefLabel":"Technology sector"}]}`))
+	}))
+	defer s.Close()
+
+	api := NewSearchAPI(testClient, s.URL+"/concepts", "apiKey")
+	concepts, err := api.SearchConcepts(tidUtils.TransactionAwareContext(context.Background(), "tid_search"), "tech",
+		[]string{"http://www.ft.com/ontology/Topic", "http://www.ft.com/ontology/Location"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Concept{
+		{
+			ID:        "http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4",
+			ApiUrl:    "http://api.ft.com/things/5507ab98-b747-3ebc-b816-11603b9009f4",
+			Type:      "http://www.ft.com/ontology/Topic",
+			PrefLabel: "Technology sector",
+		},
+	}, concepts)
+	assert.Equal(t, s.URL+"/concepts", api.Endpoint())
+}
+
+func TestSearchConceptsUnexpectedStatus(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	api := NewSearchAPI(testClient, s.URL+"/concepts", "apiKey")
+	_, err := api.SearchConcepts(context.Background(), "tech", nil)
+	assert.True(t, errors.Is(err, ErrUnexpectedSearchResponse))
+}
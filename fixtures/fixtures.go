@@ -0,0 +1,282 @@
+package fixtures
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/concept"
+	"github.com/Financial-Times/draft-annotations-api/suggestion"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	annotationsPathTemplate  = "/content/%v/annotations"
+	concordancesPath         = "/internalconcordances"
+	conceptSearchPath        = "/concepts"
+	suggestionsPathTemplate  = "/content/%v/suggestions"
+	syntheticContentUUID     = "4f2f97ea-b8ec-11e4-b8e6-00144feab7de"
+	fixturesEndpointTemplate = "fixtures://%s%s"
+)
+
+var draftAnnotationsPath = regexp.MustCompile(`^/drafts/content/([^/]+)/annotations$`)
+
+type ersatzFixtures struct {
+	Fixtures map[string]map[string]ersatzResponse `yaml:"fixtures"`
+}
+
+type ersatzResponse struct {
+	Status int         `yaml:"status"`
+	Body   interface{} `yaml:"body"`
+}
+
+// Fixtures serves the canned responses of an ersatz fixtures file in-process,
+// in place of the UPP services the API depends on.
+type Fixtures struct {
+	path      string
+	responses map[string]response
+}
+
+type response struct {
+	status int
+	body   []byte
+}
+
+// Load reads the ersatz fixtures file at the given path.
+func Load(path string) (*Fixtures, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ef ersatzFixtures
+	if err := yaml.Unmarshal(b, &ef); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures file: %w", err)
+	}
+
+	f := &Fixtures{path: path, responses: make(map[string]response)}
+	for path, methods := range ef.Fixtures {
+		get, found := methods["get"]
+		if !found {
+			continue
+		}
+		var body []byte
+		if get.Body != nil {
+			body, err = json.Marshal(toJSONValue(get.Body))
+			if err != nil {
+				return nil, fmt.Errorf("invalid body of fixture %s: %w", path, err)
+			}
+		}
+		f.responses[path] = response{status: get.Status, body: body}
+	}
+	return f, nil
+}
+
+// toJSONValue converts the maps decoded by yaml, which have interface{} keys, to maps that can be encoded as JSON.
+func toJSONValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			m[fmt.Sprint(k)] = toJSONValue(item)
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = toJSONValue(item)
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+func (f *Fixtures) get(path string) (response, bool) {
+	resp, found := f.responses[path]
+	return resp, found
+}
+
+// AnnotationsAPI returns the published annotations fixtures, served as the UPP Public Annotations API would.
+func (f *Fixtures) AnnotationsAPI() *AnnotationsAPI {
+	return &AnnotationsAPI{f}
+}
+
+// ConceptReadAPI returns the internal concordances fixtures as a concept.ReadAPI.
+func (f *Fixtures) ConceptReadAPI() concept.ReadAPI {
+	return &conceptReadAPI{f}
+}
+
+// ConceptSearchAPI returns the concepts fixtures as a concept.SearchAPI, searched in-process.
+func (f *Fixtures) ConceptSearchAPI() concept.SearchAPI {
+	return &conceptSearchAPI{f}
+}
+
+// SuggestionAPI returns the suggestions fixtures as a suggestion.API.
+func (f *Fixtures) SuggestionAPI() suggestion.API {
+	return &suggestionAPI{f}
+}
+
+// SeedRW writes the draft annotations fixtures to the given RW.
+func (f *Fixtures) SeedRW(ctx context.Context, rw annotations.RW) error {
+	for path, resp := range f.responses {
+		m := draftAnnotationsPath.FindStringSubmatch(path)
+		if m == nil || resp.status != http.StatusOK {
+			continue
+		}
+		var draft annotations.Annotations
+		if err := json.Unmarshal(resp.body, &draft); err != nil {
+			return fmt.Errorf("invalid draft annotations fixture %s: %w", path, err)
+		}
+		if _, err := rw.Write(ctx, m[1], &draft, ""); err != nil {
+			return fmt.Errorf("failed to seed draft annotations for %s: %w", m[1], err)
+		}
+	}
+	return nil
+}
+
+// AnnotationsAPI serves published annotations from fixtures.
+// Fixtures carry no lifecycle information, so GetEditable and GetByLifecycles return the same annotations as GetAll.
+type AnnotationsAPI struct {
+	fixtures *Fixtures
+}
+
+// GetAll returns the published annotations fixture for the given content.
+func (api *AnnotationsAPI) GetAll(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+	resp, found := api.fixtures.get(fmt.Sprintf(annotationsPathTemplate, contentUUID))
+	if !found {
+		return annotations.DecodeUPPAnnotations(http.StatusNotFound, nil)
+	}
+	return annotations.DecodeUPPAnnotations(resp.status, resp.body)
+}
+
+// GetEditable returns the published annotations fixture for the given content.
+func (api *AnnotationsAPI) GetEditable(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+	return api.GetAll(ctx, contentUUID)
+}
+
+// GetByLifecycles returns the published annotations fixture for the given content.
+func (api *AnnotationsAPI) GetByLifecycles(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, error) {
+	return api.GetAll(ctx, contentUUID)
+}
+
+// Endpoint returns the location of the published annotations fixtures.
+func (api *AnnotationsAPI) Endpoint() string {
+	return fmt.Sprintf(fixturesEndpointTemplate, api.fixtures.path, annotationsPathTemplate)
+}
+
+// GTG checks that the fixtures define the synthetic content used by the real API health check.
+func (api *AnnotationsAPI) GTG() error {
+	path := fmt.Sprintf(annotationsPathTemplate, syntheticContentUUID)
+	if resp, found := api.fixtures.get(path); !found || resp.status != http.StatusOK {
+		return fmt.Errorf("GTG: no successful fixture for %s: %w", path, annotations.ErrGTGNotOK)
+	}
+	return nil
+}
+
+type conceptReadAPI struct {
+	fixtures *Fixtures
+}
+
+func (api *conceptReadAPI) GetConceptsByIDs(ctx context.Context, ids []string) (map[string]concept.Concept, error) {
+	resp, found := api.fixtures.get(concordancesPath)
+	if !found || resp.status != http.StatusOK {
+		return nil, fmt.Errorf("no successful fixture for %s: %w", concordancesPath, concept.ErrUnexpectedResponse)
+	}
+
+	var result concept.SearchResult
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		return nil, err
+	}
+
+	concepts := make(map[string]concept.Concept)
+	for _, id := range ids {
+		if c, found := result.Concepts[id]; found {
+			concepts[id] = c
+		}
+	}
+	return concepts, nil
+}
+
+func (api *conceptReadAPI) Endpoint() string {
+	return fmt.Sprintf(fixturesEndpointTemplate, api.fixtures.path, concordancesPath)
+}
+
+func (api *conceptReadAPI) GTG() error {
+	if _, err := api.GetConceptsByIDs(context.Background(), nil); err != nil {
+		return fmt.Errorf("GTG: %w", err)
+	}
+	return nil
+}
+
+type conceptSearchAPI struct {
+	fixtures *Fixtures
+}
+
+// SearchConcepts returns the concepts fixtures whose prefLabel contains the query, ignoring case,
+// and whose type is among the given ones, if any.
+func (api *conceptSearchAPI) SearchConcepts(ctx context.Context, query string, types []string) ([]concept.Concept, error) {
+	resp, found := api.fixtures.get(conceptSearchPath)
+	if !found || resp.status != http.StatusOK {
+		return nil, fmt.Errorf("no successful fixture for %s: %w", conceptSearchPath, concept.ErrUnexpectedSearchResponse)
+	}
+
+	var result concept.SearchResponse
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		return nil, err
+	}
+
+	concepts := []concept.Concept{}
+	for _, c := range result.Concepts {
+		if !strings.Contains(strings.ToLower(c.PrefLabel), strings.ToLower(query)) {
+			continue
+		}
+		if len(types) > 0 && !contains(types, c.Type) {
+			continue
+		}
+		concepts = append(concepts, c)
+	}
+	return concepts, nil
+}
+
+func (api *conceptSearchAPI) Endpoint() string {
+	return fmt.Sprintf(fixturesEndpointTemplate, api.fixtures.path, conceptSearchPath)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+type suggestionAPI struct {
+	fixtures *Fixtures
+}
+
+func (api *suggestionAPI) GetSuggestions(ctx context.Context, contentUUID string) ([]suggestion.Suggestion, error) {
+	path := fmt.Sprintf(suggestionsPathTemplate, contentUUID)
+	resp, found := api.fixtures.get(path)
+	switch {
+	case !found || resp.status == http.StatusNotFound:
+		return []suggestion.Suggestion{}, nil
+	case resp.status != http.StatusOK:
+		return nil, fmt.Errorf("fixture %s has status %d: %w", path, resp.status, suggestion.ErrUnexpectedResponse)
+	}
+
+	var result suggestion.Result
+	if err := json.Unmarshal(resp.body, &result); err != nil {
+		return nil, err
+	}
+	return result.Suggestions, nil
+}
+
+func (api *suggestionAPI) Endpoint() string {
+	return fmt.Sprintf(fixturesEndpointTemplate, api.fixtures.path, suggestionsPathTemplate)
+}
@@ -0,0 +1,163 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/concept"
+	"github.com/Financial-Times/draft-annotations-api/suggestion"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testFixturesFile = "../_ft/ersatz-fixtures.yml"
+	testContentUUID  = "8df16ae8-0dfd-4859-a5ff-eeb9644bed35"
+)
+
+func TestFixturesAnnotationsAPI(t *testing.T) {
+	f, err := Load(testFixturesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := f.AnnotationsAPI()
+
+	expected := []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4",
+			ApiUrl:    "http://api.ft.com/things/5507ab98-b747-3ebc-b816-11603b9009f4",
+			Type:      "http://www.ft.com/ontology/Topic",
+			PrefLabel: "Technology sector",
+		},
+	}
+
+	actual, err := api.GetAll(context.Background(), testContentUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	actual, err = api.GetEditable(context.Background(), testContentUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+
+	_, err = api.GetAll(context.Background(), "db4daee0-2b84-465a-addb-fc8938a608db")
+	var uppErr annotations.UPPError
+	assert.True(t, errors.As(err, &uppErr))
+	assert.Equal(t, http.StatusNotFound, uppErr.Status())
+
+	assert.NoError(t, api.GTG())
+	assert.Equal(t, "fixtures://"+testFixturesFile+"/content/%v/annotations", api.Endpoint())
+}
+
+func TestFixturesConceptReadAPI(t *testing.T) {
+	f, err := Load(testFixturesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := f.ConceptReadAPI()
+
+	concepts, err := api.GetConceptsByIDs(context.Background(), []string{"ababe00a-d732-4690-b283-585e7f264d2f", "db4daee0-2b84-465a-addb-fc8938a608db"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]concept.Concept{
+		"ababe00a-d732-4690-b283-585e7f264d2f": {
+			ID:             "http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+			ApiUrl:         "http://api.ft.com/things/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+			Type:           "http://www.ft.com/ontology/Section",
+			PrefLabel:      "FT Confidential Research",
+			Aliases:        []string{"FTCR"},
+			DescriptionXML: "<p>Independent research on China and South East Asia.</p>",
+			BroaderConcepts: []concept.BroaderConcept{
+				{
+					ID:        "http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54",
+					ApiUrl:    "http://api.ft.com/things/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54",
+					Type:      "http://www.ft.com/ontology/product/Brand",
+					PrefLabel: "Financial Times",
+				},
+			},
+		},
+	}, concepts)
+
+	assert.NoError(t, api.GTG())
+}
+
+func TestFixturesSeedRW(t *testing.T) {
+	f, err := Load(testFixturesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rw := annotations.NewInMemoryRW()
+
+	err = f.SeedRW(context.Background(), rw)
+	assert.NoError(t, err)
+
+	draft, hash, found, err := rw.Read(context.Background(), testContentUUID)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.NotEmpty(t, hash)
+	assert.Equal(t, []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/ababe00a-d732-4690-b283-585e7f264d2f",
+		},
+	}, draft.Annotations)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load("./not-existing-fixtures.yml")
+	assert.Error(t, err)
+}
+
+func TestFixturesSuggestionAPI(t *testing.T) {
+	f, err := Load(testFixturesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := f.SuggestionAPI()
+
+	suggestions, err := api.GetSuggestions(context.Background(), testContentUUID)
+	assert.NoError(t, err)
+	assert.Len(t, suggestions, 2)
+	assert.Equal(t, suggestion.Suggestion{
+		ConceptId: "http://api.ft.com/things/5507ab98-b747-3ebc-b816-11603b9009f4",
+		Predicate: "http://www.ft.com/ontology/annotation/mentions",
+		Score:     0.64,
+	}, suggestions[1])
+
+	suggestions, err = api.GetSuggestions(context.Background(), "db4daee0-2b84-465a-addb-fc8938a608db")
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	assert.Equal(t, "fixtures://"+testFixturesFile+"/content/%v/suggestions", api.Endpoint())
+}
+
+func TestFixturesConceptSearchAPI(t *testing.T) {
+	f, err := Load(testFixturesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := f.ConceptSearchAPI()
+
+	concepts, err := api.SearchConcepts(context.Background(), "TECHNOLOGY", nil)
+	assert.NoError(t, err)
+	assert.Len(t, concepts, 2)
+
+	concepts, err = api.SearchConcepts(context.Background(), "technology", []string{"http://www.ft.com/ontology/person/Person"})
+	assert.NoError(t, err)
+	assert.Equal(t, []concept.Concept{
+		{
+			ID:         "http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a",
+			ApiUrl:     "http://api.ft.com/things/d7113d1d-ed66-3adf-9910-1f62b2c40e6a",
+			Type:       "http://www.ft.com/ontology/person/Person",
+			PrefLabel:  "Technology Correspondent",
+			IsFTAuthor: true,
+		},
+	}, concepts)
+
+	concepts, err = api.SearchConcepts(context.Background(), "nothing like it", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, concepts)
+
+	assert.Equal(t, "fixtures://"+testFixturesFile+"/concepts", api.Endpoint())
+}
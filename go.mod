@@ -22,8 +22,7 @@ require (
 	github.com/sirupsen/logrus v0.0.0-20170713114250-a3f95b5c4235
 	github.com/stretchr/objx v0.1.1 // indirect
 	github.com/stretchr/testify v0.0.0-20180319223459-c679ae2cc0cb
-	golang.org/x/net v0.0.0-20170719084000-02ac38e2528f // indirect
-	golang.org/x/sys v0.0.0-20170718161335-cd2c276457ed // indirect
 	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
-	gopkg.in/yaml.v2 v2.0.0-20170721122051-25c4ec802a7d // indirect
+	gopkg.in/yaml.v2 v2.0.0-20170721122051-25c4ec802a7d
+	modernc.org/sqlite v1.14.6
 )
@@ -0,0 +1,374 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Outcomes of replacing concepts in a single draft.
+const (
+	BulkStatusUpdated     = "updated"
+	BulkStatusWouldUpdate = "would-update"
+	BulkStatusUnchanged   = "unchanged"
+	BulkStatusNoDraft     = "no-draft"
+	BulkStatusInvalidUUID = "invalid-uuid"
+	BulkStatusFailed      = "failed"
+	BulkStatusAlreadyDone = "already-done"
+)
+
+const conceptMappingSeparator = "="
+
+// Limits of a single request to the bulk concept replacement admin endpoint,
+// so that it completes within the HTTP timeouts. Larger batches are handled by the replace-concepts command.
+const (
+	MaxBulkReplaceContent  = 100
+	MaxBulkReplaceMappings = 100
+)
+
+// BulkReplaceRequest is the body accepted by the bulk concept replacement admin endpoint.
+// Mapping keys are the concept IDs to be replaced and values the concept IDs replacing them;
+// an empty value removes the concept from the drafts.
+// DryRun defaults to true, so the drafts are only written when it is explicitly set to false.
+type BulkReplaceRequest struct {
+	Mapping      map[string]string `json:"mapping"`
+	ContentUUIDs []string          `json:"contentUUIDs"`
+	DryRun       *bool             `json:"dryRun,omitempty"`
+}
+
+// BulkReplaceResult describes the outcome of replacing concepts in the draft of a single piece of content.
+type BulkReplaceResult struct {
+	ContentUUID string                   `json:"uuid"`
+	Status      string                   `json:"status"`
+	Replaced    int                      `json:"replaced,omitempty"`
+	Removed     int                      `json:"removed,omitempty"`
+	Hash        string                   `json:"hash,omitempty"`
+	Error       string                   `json:"error,omitempty"`
+	Annotations []annotations.Annotation `json:"annotations,omitempty"`
+}
+
+// BulkReplaceReport collects the outcomes of a bulk concept replacement.
+type BulkReplaceReport struct {
+	DryRun  bool                `json:"dryRun"`
+	Summary map[string]int      `json:"summary"`
+	Results []BulkReplaceResult `json:"results"`
+}
+
+// ProgressLog keeps track of the drafts processed by a bulk concept replacement, so an interrupted run can be resumed.
+// Drafts are only done for the concept mapping they have been written with, identified by its fingerprint.
+type ProgressLog interface {
+	Done(mapping string, contentUUID string) bool
+	Record(entry ProgressEntry) error
+}
+
+// ProgressEntry is a result recorded in a progress log, along with the fingerprint of the concept mapping
+// it has been processed with and whether it was a dry run.
+type ProgressEntry struct {
+	Mapping string `json:"mapping"`
+	DryRun  bool   `json:"dryRun,omitempty"`
+	BulkReplaceResult
+}
+
+// ReplaceConcepts rewrites or removes the concepts in mapping from the drafts of the given content.
+// Each draft goes through the same augmentation and canonicalization as any other write and is written
+// using the hash it has been read with, so concurrent edits are never overwritten.
+// In dry-run mode nothing is written and the report contains the annotations each draft would be updated to.
+// The progress log is optional; drafts it reports as done with the same mapping are skipped.
+// Dry runs are recorded as such, and never mark drafts as done.
+func (h *Handler) ReplaceConcepts(ctx context.Context, mapping map[string]string, contentUUIDs []string, dryRun bool, progress ProgressLog) (*BulkReplaceReport, error) {
+	mapping, err := normaliseConceptMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := mappingFingerprint(mapping)
+	report := &BulkReplaceReport{DryRun: dryRun, Summary: make(map[string]int)}
+	for _, contentUUID := range contentUUIDs {
+		var result BulkReplaceResult
+		if progress != nil && !dryRun && progress.Done(fingerprint, contentUUID) {
+			result = BulkReplaceResult{ContentUUID: contentUUID, Status: BulkStatusAlreadyDone}
+		} else {
+			result = h.replaceConceptsInDraft(ctx, contentUUID, mapping, dryRun)
+			if progress != nil {
+				if err := progress.Record(ProgressEntry{Mapping: fingerprint, DryRun: dryRun, BulkReplaceResult: result}); err != nil {
+					return report, fmt.Errorf("failed to record progress: %w", err)
+				}
+			}
+		}
+		report.Summary[result.Status]++
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+func (h *Handler) replaceConceptsInDraft(ctx context.Context, contentUUID string, mapping map[string]string, dryRun bool) BulkReplaceResult {
+	result := BulkReplaceResult{ContentUUID: contentUUID}
+
+	tID, err := tidutils.GetTransactionIDFromContext(ctx)
+	if err != nil {
+		tID = tidutils.NewTransactionID()
+	}
+	ctx, cancel := context.WithTimeout(tidutils.TransactionAwareContext(ctx, tID), h.timeout)
+	defer cancel()
+	writeLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("uuid", contentUUID)
+
+	if err := validateUUID(contentUUID); err != nil {
+		result.Status = BulkStatusInvalidUUID
+		result.Error = err.Error()
+		return result
+	}
+
+	draft, hash, hasDraft, err := h.annotationsRW.Read(ctx, contentUUID)
+	if err != nil {
+		return failedBulkResult(result, "Error reading draft annotations", err, writeLog)
+	}
+	if !hasDraft {
+		result.Status = BulkStatusNoDraft
+		return result
+	}
+
+	var replaced []annotations.Annotation
+	for _, ann := range draft.Annotations {
+		replacement, found := mapping[mapper.TransformConceptID(ann.ConceptId)]
+		if !found {
+			replaced = append(replaced, ann)
+			continue
+		}
+		if replacement == "" {
+			result.Removed++
+			continue
+		}
+		ann.ConceptId = replacement
+		replaced = append(replaced, ann)
+		result.Replaced++
+	}
+
+	if result.Replaced == 0 && result.Removed == 0 {
+		result.Status = BulkStatusUnchanged
+		result.Hash = hash
+		return result
+	}
+
+	if dryRun {
+		prepared, err := h.prepareAnnotationsForWrite(ctx, replaced, writeLog)
+		if err != nil {
+			return failedBulkResult(result, "Error preparing draft annotations", err, writeLog)
+		}
+		result.Status = BulkStatusWouldUpdate
+		result.Hash = hash
+		result.Annotations = prepared.annotations.Annotations
+		return result
+	}
+
+	saved, err := h.saveAndReturnAnnotations(ctx, replaced, writeLog, hash, contentUUID)
+	if err != nil {
+		return failedBulkResult(result, "Error writing draft annotations", err, writeLog)
+	}
+	if !saved.written {
+		result.Status = BulkStatusUnchanged
+		result.Hash = saved.hash
+		return result
+	}
+	writeLog.WithField("replaced", result.Replaced).WithField("removed", result.Removed).Info("Concepts replaced in draft annotations")
+	result.Status = BulkStatusUpdated
+	result.Hash = saved.hash
+	return result
+}
+
+func failedBulkResult(result BulkReplaceResult, msg string, err error, writeLog *log.Entry) BulkReplaceResult {
+	writeLog.WithError(err).Error(msg)
+	result.Status = BulkStatusFailed
+	result.Error = fmt.Sprintf("%s: %v", msg, err)
+	return result
+}
+
+// BulkReplaceConcepts is the admin endpoint replacing or removing concepts across the drafts of many pieces of content.
+func (h *Handler) BulkReplaceConcepts(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	tID := tidutils.GetTransactionIDFromRequest(r)
+	ctx := tidutils.TransactionAwareContext(context.Background(), tID)
+	writeLog := log.WithField(tidutils.TransactionIDKey, tID)
+
+	var req BulkReplaceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		handleWriteErrors("Error decoding request body", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+	if len(req.Mapping) == 0 || len(req.ContentUUIDs) == 0 {
+		handleWriteErrors("Invalid request", CodeInvalidRequest, errors.New("mapping and contentUUIDs are required"), writeLog, w, http.StatusBadRequest)
+		return
+	}
+	if len(req.ContentUUIDs) > MaxBulkReplaceContent || len(req.Mapping) > MaxBulkReplaceMappings {
+		err := fmt.Errorf("at most %d contentUUIDs and %d mappings are allowed per request", MaxBulkReplaceContent, MaxBulkReplaceMappings)
+		handleWriteErrors("Invalid request", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+	dryRun := req.DryRun == nil || *req.DryRun
+
+	report, err := h.ReplaceConcepts(ctx, req.Mapping, req.ContentUUIDs, dryRun, nil)
+	if err != nil {
+		handleWriteErrors("Invalid request", CodeInvalidConceptID, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		writeLog.WithError(err).Error("Failed to encode bulk replace report")
+	}
+}
+
+// ParseConceptMapping parses concept mappings in the OLD=NEW form, where an empty NEW removes the concept.
+func ParseConceptMapping(pairs []string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, pair := range pairs {
+		i := strings.Index(pair, conceptMappingSeparator)
+		if i == -1 {
+			return nil, fmt.Errorf("invalid concept mapping %q: expected OLD=NEW", pair)
+		}
+		mapping[strings.TrimSpace(pair[:i])] = strings.TrimSpace(pair[i+1:])
+	}
+	return mapping, nil
+}
+
+// ReadContentUUIDs reads content UUIDs, one per line, skipping blank lines.
+func ReadContentUUIDs(r io.Reader) ([]string, error) {
+	var uuids []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			uuids = append(uuids, line)
+		}
+	}
+	return uuids, scanner.Err()
+}
+
+func normaliseConceptMapping(mapping map[string]string) (map[string]string, error) {
+	normalised := make(map[string]string, len(mapping))
+	for from, to := range mapping {
+		fromID, err := normaliseConceptID(from)
+		if err != nil {
+			return nil, err
+		}
+		toID := ""
+		if to != "" {
+			if toID, err = normaliseConceptID(to); err != nil {
+				return nil, err
+			}
+		}
+		normalised[fromID] = toID
+	}
+	return normalised, nil
+}
+
+func normaliseConceptID(id string) (string, error) {
+	conceptID := mapper.TransformConceptID("/" + id)
+	if conceptID == "" {
+		return "", fmt.Errorf("invalid concept ID %q", id)
+	}
+	if err := validateUUID(extractConceptUUID(conceptID)); err != nil {
+		return "", fmt.Errorf("invalid concept ID %q: %w", id, err)
+	}
+	return conceptID, nil
+}
+
+// mappingFingerprint identifies a normalised concept mapping, regardless of the order of its entries.
+func mappingFingerprint(mapping map[string]string) string {
+	entries := make([]string, 0, len(mapping))
+	for from, to := range mapping {
+		entries = append(entries, from+conceptMappingSeparator+to)
+	}
+	sort.Strings(entries)
+
+	sum := sha256.Sum256([]byte(strings.Join(entries, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func extractConceptUUID(conceptID string) string {
+	return conceptID[strings.LastIndex(conceptID, "/")+1:]
+}
+
+// FileProgressLog is a ProgressLog appending one JSON encoded entry per line to a file.
+// Drafts recorded as failed or in dry runs are processed again when the run is resumed.
+type FileProgressLog struct {
+	file *os.File
+	done map[progressKey]struct{}
+}
+
+type progressKey struct {
+	mapping     string
+	contentUUID string
+}
+
+// NewFileProgressLog opens the progress log at path, loading the drafts already processed by previous runs.
+func NewFileProgressLog(path string) (*FileProgressLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &FileProgressLog{file: f, done: make(map[progressKey]struct{})}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry ProgressEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("invalid progress log entry %q: %w", scanner.Text(), err)
+		}
+		l.load(entry)
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *FileProgressLog) load(entry ProgressEntry) {
+	if entry.DryRun {
+		return
+	}
+	key := progressKey{mapping: entry.Mapping, contentUUID: entry.ContentUUID}
+	if entry.Status == BulkStatusFailed {
+		delete(l.done, key)
+		return
+	}
+	l.done[key] = struct{}{}
+}
+
+// Done reports whether the draft of the given content has already been processed with the mapping.
+func (l *FileProgressLog) Done(mapping string, contentUUID string) bool {
+	_, found := l.done[progressKey{mapping: mapping, contentUUID: contentUUID}]
+	return found
+}
+
+// Record appends the entry to the progress log.
+func (l *FileProgressLog) Record(entry ProgressEntry) error {
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	l.load(entry)
+	return nil
+}
+
+// Close closes the underlying file.
+func (l *FileProgressLog) Close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,272 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	bulkContentUUID      = "83a201c6-60cd-11e7-91a7-502f7ee26895"
+	bulkOtherContentUUID = "4f2f97ea-b8ec-11e4-b8e6-00144feab7de"
+	bulkNoDraftUUID      = "db4daee0-2b84-465a-addb-fc8938a608db"
+	bulkOldConceptUUID   = "9577c6d4-b09e-4552-b88f-e52745abe02b"
+	bulkNewConceptUUID   = "100e3cc0-aecc-4458-8ebd-6b1fbc7345ed"
+	bulkRemovedConceptID = "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"
+)
+
+var bulkDraft = &annotations.Annotations{
+	Annotations: []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: bulkRemovedConceptID,
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/" + bulkOldConceptUUID,
+		},
+	},
+}
+
+var bulkReplaced = []annotations.Annotation{
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/about",
+		ConceptId: "http://www.ft.com/thing/" + bulkNewConceptUUID,
+	},
+}
+
+func newBulkHandler(rw *RWMock) *handler.Handler {
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			return depletedAnnotations, nil
+		},
+	}
+	return handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
+}
+
+func TestReplaceConcepts(t *testing.T) {
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, bulkContentUUID).Return(bulkDraft, "old-hash", true, nil)
+	rw.On("Read", mock.Anything, bulkOtherContentUUID).Return(&annotations.Annotations{Annotations: bulkReplaced}, "other-hash", true, nil)
+	rw.On("Read", mock.Anything, bulkNoDraftUUID).Return(nil, "", false, nil)
+	rw.On("Write", mock.Anything, bulkContentUUID, &annotations.Annotations{Annotations: bulkReplaced}, "old-hash").Return("new-hash", nil)
+
+	h := newBulkHandler(rw)
+	mapping := map[string]string{
+		bulkOldConceptUUID:   bulkNewConceptUUID,
+		bulkRemovedConceptID: "",
+	}
+
+	report, err := h.ReplaceConcepts(context.Background(), mapping, []string{bulkContentUUID, bulkOtherContentUUID, bulkNoDraftUUID, "not-a-uuid"}, false, nil)
+	assert.NoError(t, err)
+
+	assert.False(t, report.DryRun)
+	assert.Equal(t, []handler.BulkReplaceResult{
+		{ContentUUID: bulkContentUUID, Status: handler.BulkStatusUpdated, Replaced: 1, Removed: 1, Hash: "new-hash"},
+		{ContentUUID: bulkOtherContentUUID, Status: handler.BulkStatusUnchanged, Hash: "other-hash"},
+		{ContentUUID: bulkNoDraftUUID, Status: handler.BulkStatusNoDraft},
+		{ContentUUID: "not-a-uuid", Status: handler.BulkStatusInvalidUUID, Error: report.Results[3].Error},
+	}, report.Results)
+	assert.NotEmpty(t, report.Results[3].Error)
+	assert.Equal(t, 1, report.Summary[handler.BulkStatusUpdated])
+
+	rw.AssertExpectations(t)
+}
+
+func TestReplaceConceptsDryRun(t *testing.T) {
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, bulkContentUUID).Return(bulkDraft, "old-hash", true, nil)
+
+	h := newBulkHandler(rw)
+	mapping := map[string]string{
+		"http://www.ft.com/thing/" + bulkOldConceptUUID: bulkNewConceptUUID,
+		bulkRemovedConceptID:                            "",
+	}
+
+	report, err := h.ReplaceConcepts(context.Background(), mapping, []string{bulkContentUUID}, true, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Len(t, report.Results, 1)
+	assert.Equal(t, handler.BulkStatusWouldUpdate, report.Results[0].Status)
+	assert.Equal(t, "old-hash", report.Results[0].Hash)
+	assert.Equal(t, bulkReplaced, report.Results[0].Annotations)
+
+	rw.AssertExpectations(t)
+	rw.AssertNotCalled(t, "Write", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestReplaceConceptsInvalidMapping(t *testing.T) {
+	h := newBulkHandler(new(RWMock))
+
+	_, err := h.ReplaceConcepts(context.Background(), map[string]string{"not-a-uuid": bulkNewConceptUUID}, []string{bulkContentUUID}, false, nil)
+	assert.Error(t, err)
+}
+
+func TestReplaceConceptsResumesFromProgressLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace-concepts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress.log")
+
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, bulkContentUUID).Return(bulkDraft, "old-hash", true, nil).Twice()
+	rw.On("Write", mock.Anything, bulkContentUUID, mock.Anything, "old-hash").Return("new-hash", nil).Once()
+	rw.On("Read", mock.Anything, bulkNoDraftUUID).Return(nil, "", false, nil).Once()
+	h := newBulkHandler(rw)
+	mapping := map[string]string{bulkOldConceptUUID: bulkNewConceptUUID}
+
+	progress, err := handler.NewFileProgressLog(path)
+	assert.NoError(t, err)
+	_, err = h.ReplaceConcepts(context.Background(), mapping, []string{bulkContentUUID, bulkNoDraftUUID}, false, progress)
+	assert.NoError(t, err)
+	assert.NoError(t, progress.Close())
+
+	resumed, err := handler.NewFileProgressLog(path)
+	assert.NoError(t, err)
+	defer resumed.Close()
+	report, err := h.ReplaceConcepts(context.Background(), mapping, []string{bulkContentUUID, bulkNoDraftUUID}, false, resumed)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, report.Summary[handler.BulkStatusAlreadyDone])
+	rw.AssertExpectations(t)
+}
+
+func TestReplaceConceptsResumesOnlyWrittenDraftsWithTheSameMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replace-concepts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "progress.log")
+
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, bulkContentUUID).Return(bulkDraft, "old-hash", true, nil).Times(5)
+	rw.On("Write", mock.Anything, bulkContentUUID, mock.Anything, "old-hash").Return("new-hash", nil).Twice()
+	h := newBulkHandler(rw)
+	mapping := map[string]string{bulkOldConceptUUID: bulkNewConceptUUID}
+
+	progress, err := handler.NewFileProgressLog(path)
+	assert.NoError(t, err)
+	_, err = h.ReplaceConcepts(context.Background(), mapping, []string{bulkContentUUID}, true, progress)
+	assert.NoError(t, err)
+	report, err := h.ReplaceConcepts(context.Background(), mapping, []string{bulkContentUUID}, false, progress)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Summary[handler.BulkStatusUpdated], "a dry run should not mark the draft as done")
+	assert.NoError(t, progress.Close())
+
+	resumed, err := handler.NewFileProgressLog(path)
+	assert.NoError(t, err)
+	defer resumed.Close()
+	report, err = h.ReplaceConcepts(context.Background(), map[string]string{bulkOldConceptUUID: ""}, []string{bulkContentUUID}, false, resumed)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, report.Summary[handler.BulkStatusUpdated], "a draft done with another mapping should be processed again")
+	rw.AssertExpectations(t)
+}
+
+func TestParseConceptMapping(t *testing.T) {
+	mapping, err := handler.ParseConceptMapping([]string{bulkOldConceptUUID + "=" + bulkNewConceptUUID, bulkRemovedConceptID + "="})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{bulkOldConceptUUID: bulkNewConceptUUID, bulkRemovedConceptID: ""}, mapping)
+
+	_, err = handler.ParseConceptMapping([]string{bulkOldConceptUUID})
+	assert.Error(t, err)
+}
+
+func TestReadContentUUIDs(t *testing.T) {
+	uuids, err := handler.ReadContentUUIDs(strings.NewReader(bulkContentUUID + "\n\n  " + bulkNoDraftUUID + "  \n"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{bulkContentUUID, bulkNoDraftUUID}, uuids)
+}
+
+func TestBulkReplaceConceptsEndpoint(t *testing.T) {
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, bulkContentUUID).Return(bulkDraft, "old-hash", true, nil)
+	h := newBulkHandler(rw)
+
+	r := vestigo.NewRouter()
+	r.Post("/__admin/concepts/replace", h.BulkReplaceConcepts)
+
+	dryRun := true
+	tooManyContentUUIDs := make([]string, handler.MaxBulkReplaceContent+1)
+	for i := range tooManyContentUUIDs {
+		tooManyContentUUIDs[i] = bulkContentUUID
+	}
+
+	tests := map[string]struct {
+		body           handler.BulkReplaceRequest
+		expectedStatus int
+	}{
+		"dry run": {
+			body: handler.BulkReplaceRequest{
+				Mapping:      map[string]string{bulkOldConceptUUID: bulkNewConceptUUID},
+				ContentUUIDs: []string{bulkContentUUID},
+				DryRun:       &dryRun,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"dry run by default": {
+			body: handler.BulkReplaceRequest{
+				Mapping:      map[string]string{bulkOldConceptUUID: bulkNewConceptUUID},
+				ContentUUIDs: []string{bulkContentUUID},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"too many content UUIDs": {
+			body: handler.BulkReplaceRequest{
+				Mapping:      map[string]string{bulkOldConceptUUID: bulkNewConceptUUID},
+				ContentUUIDs: tooManyContentUUIDs,
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"missing content UUIDs": {
+			body: handler.BulkReplaceRequest{
+				Mapping: map[string]string{bulkOldConceptUUID: bulkNewConceptUUID},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid mapping": {
+			body: handler.BulkReplaceRequest{
+				Mapping:      map[string]string{bulkOldConceptUUID: "not-a-uuid"},
+				ContentUUIDs: []string{bulkContentUUID},
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			b, _ := json.Marshal(test.body)
+			req := httptest.NewRequest("POST", "/__admin/concepts/replace", bytes.NewBuffer(b))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			if test.expectedStatus == http.StatusOK {
+				var report handler.BulkReplaceReport
+				assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+				assert.True(t, report.DryRun)
+				assert.Equal(t, 1, report.Summary[handler.BulkStatusWouldUpdate])
+			}
+		})
+	}
+	rw.AssertNotCalled(t, "Write", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
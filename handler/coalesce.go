@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	metrics "github.com/rcrowley/go-metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Names of the counters of the reads of annotations.
+// Executed reads called the annotations RW, UPP and the concepts API, coalesced reads waited for the result
+// of an identical read in flight instead, and cached reads were served from the read cache.
+const (
+	MetricReadsExecuted  = "annotations.reads.executed"
+	MetricReadsCoalesced = "annotations.reads.coalesced"
+	MetricReadsCached    = "annotations.reads.cached"
+)
+
+// MaxSharedReadTimeout bounds the shared reads, which are not cancelled with the requests waiting for them.
+// They run with the timeout of the handler when it is shorter.
+const MaxSharedReadTimeout = 5 * time.Second
+
+// WithReadCache keeps the results of the reads for ttl. Writes through the handler invalidate the results
+// for their content, but writes through other instances are only seen once the results expire.
+func WithReadCache(ttl time.Duration) Option {
+	return func(h *Handler) {
+		h.readCacheTTL = ttl
+	}
+}
+
+// WithMetricsRegistry registers the metrics of the handler in r instead of a registry of its own.
+func WithMetricsRegistry(r metrics.Registry) Option {
+	return func(h *Handler) {
+		h.metrics = r
+	}
+}
+
+type readResult struct {
+	annotations     []annotations.Annotation
+	hash            string
+	degraded        bool
+	publishedCached bool
+	// missingLifecycles are the lifecycles UPP failed to return the published annotations of.
+	missingLifecycles []string
+}
+
+type readCall struct {
+	done   chan struct{}
+	result readResult
+	err    error
+	// stale is set when the content is written while the call is in flight, so that its result is not cached.
+	stale bool
+}
+
+type cachedRead struct {
+	result  readResult
+	expires time.Time
+}
+
+// readCoalescer runs a single read at a time for each content and showHasBrand value,
+// whose result is shared with the identical reads received meanwhile and optionally cached.
+type readCoalescer struct {
+	sync.Mutex
+	calls    map[string]*readCall
+	cache    map[string]cachedRead
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	executed  metrics.Counter
+	coalesced metrics.Counter
+	cached    metrics.Counter
+}
+
+func newReadCoalescer(cacheTTL time.Duration, r metrics.Registry) *readCoalescer {
+	return &readCoalescer{
+		calls:     make(map[string]*readCall),
+		cache:     make(map[string]cachedRead),
+		cacheTTL:  cacheTTL,
+		now:       time.Now,
+		executed:  metrics.GetOrRegisterCounter(MetricReadsExecuted, r),
+		coalesced: metrics.GetOrRegisterCounter(MetricReadsCoalesced, r),
+		cached:    metrics.GetOrRegisterCounter(MetricReadsCached, r),
+	}
+}
+
+// do returns the cached result of the read, or waits for the identical read in flight, or calls read.
+// The results are shared, so they must not be modified.
+// Waiting stops when ctx is done, but read is not cancelled as other reads may be waiting for it.
+func (c *readCoalescer) do(ctx context.Context, contentUUID string, showHasBrand bool, read func() (readResult, error)) (readResult, error) {
+	key := readKey(contentUUID, showHasBrand)
+
+	c.Lock()
+	if r, found := c.cache[key]; found && c.now().Before(r.expires) {
+		c.Unlock()
+		c.cached.Inc(1)
+		return r.result, nil
+	}
+	if call, found := c.calls[key]; found {
+		c.Unlock()
+		c.coalesced.Inc(1)
+		select {
+		case <-call.done:
+			return call.result, call.err
+		case <-ctx.Done():
+			return readResult{}, ctx.Err()
+		}
+	}
+	call := &readCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.Unlock()
+	c.executed.Inc(1)
+
+	call.result, call.err = read()
+
+	c.Lock()
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	// Degraded and partial results are not cached, so that they are complete again as soon as the upstream APIs recover.
+	if call.err == nil && !call.stale && !call.result.degraded && len(call.result.missingLifecycles) == 0 && c.cacheTTL > 0 {
+		now := c.now()
+		for k, r := range c.cache {
+			if !now.Before(r.expires) {
+				delete(c.cache, k)
+			}
+		}
+		c.cache[key] = cachedRead{result: call.result, expires: now.Add(c.cacheTTL)}
+	}
+	c.Unlock()
+	close(call.done)
+
+	return call.result, call.err
+}
+
+// invalidate forgets the cached results of the content and the reads of the content in flight,
+// so that the next reads see the draft that has been written.
+func (c *readCoalescer) invalidate(contentUUID string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, showHasBrand := range []bool{false, true} {
+		c.invalidateKey(readKey(contentUUID, showHasBrand))
+	}
+}
+
+// invalidateOthers is invalidate, except for the read with the given showHasBrand value,
+// for the draft written by that read itself, whose result already reflects it.
+func (c *readCoalescer) invalidateOthers(contentUUID string, showHasBrand bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	c.invalidateKey(readKey(contentUUID, !showHasBrand))
+}
+
+func (c *readCoalescer) invalidateKey(key string) {
+	delete(c.cache, key)
+	if call, found := c.calls[key]; found {
+		call.stale = true
+		delete(c.calls, key)
+	}
+}
+
+// coalescedReadAnnotations is readAnnotations, coalesced with the identical reads in flight.
+// The shared read runs with a context of its own, so that it is not cancelled with the request that started it,
+// limited to MaxSharedReadTimeout.
+func (h *Handler) coalescedReadAnnotations(ctx context.Context, contentUUID string, showHasBrand bool, readLog *log.Entry) (readResult, error) {
+	tID, _ := tidutils.GetTransactionIDFromContext(ctx)
+	timeout := h.timeout
+	if timeout > MaxSharedReadTimeout {
+		timeout = MaxSharedReadTimeout
+	}
+	return h.reads.do(ctx, contentUUID, showHasBrand, func() (readResult, error) {
+		readCtx, cancel := context.WithTimeout(tidutils.TransactionAwareContext(context.Background(), tID), timeout)
+		defer cancel()
+		return h.readAnnotations(readCtx, contentUUID, showHasBrand, readLog)
+	})
+}
+
+func readKey(contentUUID string, showHasBrand bool) string {
+	return contentUUID + "/" + strconv.FormatBool(showHasBrand)
+}
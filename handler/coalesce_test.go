@@ -0,0 +1,229 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	metrics "github.com/rcrowley/go-metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+const coalescedContentUUID = "83a201c6-60cd-11e7-91a7-502f7ee26895"
+
+var coalescedDraft = &annotations.Annotations{Annotations: []annotations.Annotation{
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/mentions",
+		ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+	},
+}}
+
+var identityAugmenter = &AugmenterMock{
+	augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+		return depletedAnnotations, nil
+	},
+}
+
+func newCoalescedRouter(h *handler.Handler) *vestigo.Router {
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+	r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+	return r
+}
+
+func serveCoalescedRead(r http.Handler, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/drafts/content/"+coalescedContentUUID+"/annotations"+query, nil)
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func counter(registry metrics.Registry, name string) int64 {
+	return registry.Get(name).(metrics.Counter).Count()
+}
+
+func TestConcurrentReadsAreCoalesced(t *testing.T) {
+	const concurrentReads = 10
+
+	var mu sync.Mutex
+	rwReads := 0
+	release := make(chan struct{})
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			mu.Lock()
+			rwReads++
+			mu.Unlock()
+			<-release
+			return coalescedDraft, "hash", true, nil
+		},
+	}
+	registry := metrics.NewRegistry()
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second, handler.WithMetricsRegistry(registry))
+	r := newCoalescedRouter(h)
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, concurrentReads)
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i] = serveCoalescedRead(r, "")
+		}(i)
+	}
+
+	for deadline := time.Now().Add(time.Second); counter(registry, handler.MetricReadsCoalesced) < concurrentReads-1; {
+		if time.Now().After(deadline) {
+			t.Fatal("reads have not been coalesced")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 1, rwReads)
+	assert.Equal(t, int64(1), counter(registry, handler.MetricReadsExecuted))
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "hash", w.Header().Get(annotations.DocumentHashHeader))
+		assert.JSONEq(t, `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}]}`, w.Body.String())
+	}
+
+	serveCoalescedRead(r, "")
+	assert.Equal(t, 2, rwReads, "reads are not cached by default")
+}
+
+func TestReadCacheIsInvalidatedByWrites(t *testing.T) {
+	rwReads := 0
+	hash := "hash"
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			rwReads++
+			return coalescedDraft, hash, true, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, oldHash string) (string, error) {
+			hash = "new-hash"
+			return hash, nil
+		},
+	}
+	registry := metrics.NewRegistry()
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second,
+		handler.WithReadCache(time.Minute), handler.WithMetricsRegistry(registry))
+	r := newCoalescedRouter(h)
+
+	assert.Equal(t, "hash", serveCoalescedRead(r, "").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, "hash", serveCoalescedRead(r, "").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, 1, rwReads)
+	assert.Equal(t, int64(1), counter(registry, handler.MetricReadsCached))
+
+	assert.Equal(t, "hash", serveCoalescedRead(r, "?sendHasBrand=true").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, 2, rwReads, "reads with hasBrand annotations are cached separately")
+
+	req := httptest.NewRequest("PUT", "/drafts/content/"+coalescedContentUUID+"/annotations",
+		strings.NewReader(`{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}]}`))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	rwReads = 0
+
+	assert.Equal(t, "new-hash", serveCoalescedRead(r, "").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, "new-hash", serveCoalescedRead(r, "?sendHasBrand=true").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, 2, rwReads)
+}
+
+func TestReadCacheKeepsTheMigratedDraft(t *testing.T) {
+	rwReads, rwWrites := 0, 0
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			rwReads++
+			return &annotations.Annotations{Annotations: []annotations.Annotation{
+				{
+					Predicate: "http://www.ft.com/ontology/annotation/mentions",
+					ConceptId: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
+				},
+			}}, "hash", true, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, oldHash string) (string, error) {
+			rwWrites++
+			return "migrated-hash", nil
+		},
+	}
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			return []annotations.Annotation{
+				{
+					Predicate:  "http://www.ft.com/ontology/annotation/mentions",
+					ConceptId:  "http://www.ft.com/thing/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+					MergedFrom: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
+				},
+			}, nil
+		},
+	}
+	registry := metrics.NewRegistry()
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second,
+		handler.WithReadCache(time.Minute), handler.WithMetricsRegistry(registry), handler.WithMergedConceptsMigration())
+	r := newCoalescedRouter(h)
+
+	assert.Equal(t, "migrated-hash", serveCoalescedRead(r, "").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, "migrated-hash", serveCoalescedRead(r, "").Header().Get(annotations.DocumentHashHeader))
+	assert.Equal(t, 1, rwReads, "the read migrating the draft is cached")
+	assert.Equal(t, 1, rwWrites)
+	assert.Equal(t, int64(1), counter(registry, handler.MetricReadsCached))
+}
+
+func TestDegradedReadsAreNotCached(t *testing.T) {
+	rwReads := 0
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			rwReads++
+			return coalescedDraft, "hash", true, nil
+		},
+	}
+	conceptsAvailable := false
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			if !conceptsAvailable {
+				return nil, errors.New("concepts API unavailable")
+			}
+			return depletedAnnotations, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second,
+		handler.WithReadCache(time.Minute), handler.WithDegradedReads(identityAugmenter))
+	r := newCoalescedRouter(h)
+
+	assert.Equal(t, "true", serveCoalescedRead(r, "").Header().Get(handler.DegradedHeader))
+	conceptsAvailable = true
+	assert.Empty(t, serveCoalescedRead(r, "").Header().Get(handler.DegradedHeader))
+	assert.Equal(t, 2, rwReads, "degraded reads are not cached")
+
+	assert.Empty(t, serveCoalescedRead(r, "").Header().Get(handler.DegradedHeader))
+	assert.Equal(t, 2, rwReads)
+}
+
+func TestSharedReadsHaveADeadline(t *testing.T) {
+	var deadline time.Time
+	var hasDeadline bool
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			deadline, hasDeadline = ctx.Deadline()
+			return coalescedDraft, "hash", true, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Hour)
+	r := newCoalescedRouter(h)
+
+	assert.Equal(t, http.StatusOK, serveCoalescedRead(r, "").Code)
+	assert.True(t, hasDeadline)
+	assert.False(t, deadline.After(time.Now().Add(handler.MaxSharedReadTimeout)), "the shared read is limited whatever the timeout of the handler")
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	log "github.com/sirupsen/logrus"
+)
+
+// WithConceptIndex keeps idx up to date with the drafts written, and serves from it the drafts referencing a concept.
+// Without it, the drafts referencing a concept are unavailable.
+func WithConceptIndex(idx annotations.ConceptIndex) Option {
+	return func(h *Handler) {
+		h.conceptIndex = idx
+	}
+}
+
+// ConceptContent lists the contents whose draft annotations reference a concept.
+type ConceptContent struct {
+	ConceptID    string   `json:"id"`
+	ContentUUIDs []string `json:"contentUUIDs"`
+}
+
+// ConceptIndexRebuildRequest is the body accepted by the concept index rebuild admin endpoint.
+// Without content UUIDs, every draft is indexed again, provided the annotations RW can list them.
+type ConceptIndexRebuildRequest struct {
+	ContentUUIDs []string `json:"contentUUIDs"`
+}
+
+// indexDraft updates the concept index with the draft annotations written for the content.
+// The draft is written already, so failing to index it is only logged.
+func (h *Handler) indexDraft(ctx context.Context, contentUUID string, list []annotations.Annotation, writeLog *log.Entry) {
+	if h.conceptIndex == nil {
+		return
+	}
+	if err := h.conceptIndex.Update(ctx, contentUUID, list); err != nil {
+		writeLog.WithError(err).Warn("Failed to update the concept index with the draft annotations")
+	}
+}
+
+// GetConceptContent returns the UUIDs of the contents whose draft annotations reference the concept.
+// It is unavailable until the concept index has been completely built.
+func (h *Handler) GetConceptContent(w http.ResponseWriter, r *http.Request) {
+	conceptUUID := vestigo.Param(r, "cuuid")
+	tID := tidutils.GetTransactionIDFromRequest(r)
+
+	ctx, cancel := context.WithTimeout(tidutils.TransactionAwareContext(r.Context(), tID), h.timeout)
+	defer cancel()
+
+	readLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("conceptUUID", conceptUUID)
+
+	if err := validateUUID(conceptUUID); err != nil {
+		p := newProblem(err, http.StatusBadRequest, CodeInvalidConceptID, "Invalid concept UUID: "+err.Error())
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+	if h.conceptIndex == nil {
+		p := newProblem(errors.New("no concept index configured"), http.StatusServiceUnavailable, CodeConceptIndexUnavailable, "No concept index configured")
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+
+	complete, err := h.conceptIndex.Complete(ctx)
+	if err != nil {
+		handleReadErrors(withUpstream(UpstreamConceptIndex, err), readLog, w)
+		return
+	}
+	if !complete {
+		// Serving the drafts indexed so far would silently miss the drafts written before the index was built.
+		p := newProblem(errors.New("concept index not built"), http.StatusServiceUnavailable, CodeConceptIndexUnavailable,
+			"The concept index has not been completely built yet")
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+
+	contentUUIDs, err := h.conceptIndex.ContentUUIDs(ctx, conceptUUID)
+	if err != nil {
+		handleReadErrors(withUpstream(UpstreamConceptIndex, err), readLog, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	response := ConceptContent{ConceptID: thingURIPrefix + conceptUUID, ContentUUIDs: contentUUIDs}
+	if err := json.NewEncoder(w).Encode(&response); err != nil {
+		readLog.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// RebuildConceptIndex indexes the draft annotations of the given contents again, or of every draft.
+func (h *Handler) RebuildConceptIndex(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	tID := tidutils.GetTransactionIDFromRequest(r)
+	ctx := tidutils.TransactionAwareContext(context.Background(), tID)
+	writeLog := log.WithField(tidutils.TransactionIDKey, tID)
+
+	if h.conceptIndex == nil {
+		handleWriteErrors("Concept index unavailable", CodeConceptIndexUnavailable, errors.New("no concept index configured"), writeLog, w, http.StatusServiceUnavailable)
+		return
+	}
+
+	var req ConceptIndexRebuildRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		handleWriteErrors("Error decoding request body", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+	for _, contentUUID := range req.ContentUUIDs {
+		if err := validateUUID(contentUUID); err != nil {
+			handleWriteErrors("Invalid content UUID", CodeInvalidContentUUID, err, writeLog, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	writeLog.WithField("contents", len(req.ContentUUIDs)).Info("Rebuilding the concept index")
+	indexed, err := annotations.RebuildConceptIndex(ctx, h.conceptIndex, h.annotationsRW, req.ContentUUIDs)
+	if errors.Is(err, annotations.ErrDraftsNotListable) {
+		handleWriteErrors("Invalid request", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		handleWriteErrors("Error rebuilding the concept index", CodeInternalError, withUpstream(UpstreamConceptIndex, err), writeLog, w, http.StatusInternalServerError)
+		return
+	}
+
+	writeLog.WithField("indexed", indexed).Info("Concept index rebuilt")
+	response := struct {
+		Indexed int `json:"indexed"`
+	}{Indexed: indexed}
+	if err := json.NewEncoder(w).Encode(&response); err != nil {
+		writeLog.WithError(err).Error("Failed to encode response")
+	}
+}
@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConceptIndexRouter(h *handler.Handler) *vestigo.Router {
+	r := vestigo.NewRouter()
+	r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+	r.Get("/drafts/concepts/:cuuid/content", h.GetConceptContent)
+	r.Post("/__admin/concept-index/rebuild", h.RebuildConceptIndex)
+	return r
+}
+
+func serveConceptIndex(r *vestigo.Router, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestConceptIndexIsUpdatedByWrites(t *testing.T) {
+	rw := annotations.NewInMemoryRW()
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second,
+		handler.WithConceptIndex(annotations.NewInMemoryConceptIndex()))
+	r := newConceptIndexRouter(h)
+
+	w := serveConceptIndex(r, "GET", "/drafts/concepts/d7113d1d-ed66-3adf-9910-1f62b2c40e6a/content", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "the index is incomplete until it is rebuilt")
+	assert.Contains(t, w.Body.String(), `"code":"concept_index_unavailable"`)
+
+	w = serveConceptIndex(r, "POST", "/__admin/concept-index/rebuild", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"indexed":0}`, w.Body.String())
+
+	w = serveConceptIndex(r, "PUT", "/drafts/content/"+publishedContentUUID+"/annotations", `{"annotations":[`+machineAbout+`,`+machineMentions+`]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serveConceptIndex(r, "GET", "/drafts/concepts/d7113d1d-ed66-3adf-9910-1f62b2c40e6a/content", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","contentUUIDs":["`+publishedContentUUID+`"]}`, w.Body.String())
+
+	w = serveConceptIndex(r, "PUT", "/drafts/content/"+publishedContentUUID+"/annotations", `{"annotations":[`+machineAbout+`]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = serveConceptIndex(r, "GET", "/drafts/concepts/d7113d1d-ed66-3adf-9910-1f62b2c40e6a/content", "")
+	assert.JSONEq(t, `{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","contentUUIDs":[]}`, w.Body.String())
+
+	// a draft written bypassing the handler is only indexed by a rebuild
+	_, err := rw.Write(context.Background(), "0e7a2a5c-4d3b-4b9e-8a5c-1f2d3e4f5a6b", &annotations.Annotations{Annotations: []annotations.Annotation{editorialAnnotation}}, "")
+	assert.NoError(t, err)
+
+	w = serveConceptIndex(r, "POST", "/__admin/concept-index/rebuild", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"indexed":2}`, w.Body.String())
+
+	w = serveConceptIndex(r, "GET", "/drafts/concepts/d7de27f8-1633-3fcc-b308-c95a2ad7d1cd/content", "")
+	assert.JSONEq(t, `{"id":"http://www.ft.com/thing/d7de27f8-1633-3fcc-b308-c95a2ad7d1cd","contentUUIDs":["0e7a2a5c-4d3b-4b9e-8a5c-1f2d3e4f5a6b","`+publishedContentUUID+`"]}`, w.Body.String())
+}
+
+func TestConceptIndexErrors(t *testing.T) {
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+	}
+	c14n := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
+
+	h := handler.New(rw, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second)
+	r := newConceptIndexRouter(h)
+	w := serveConceptIndex(r, "GET", "/drafts/concepts/d7113d1d-ed66-3adf-9910-1f62b2c40e6a/content", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"concept_index_unavailable"`)
+
+	h = handler.New(rw, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second, handler.WithConceptIndex(annotations.NewInMemoryConceptIndex()))
+	r = newConceptIndexRouter(h)
+	w = serveConceptIndex(r, "GET", "/drafts/concepts/not-a-uuid/content", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"invalid_concept_id"`)
+
+	// the RW mock cannot list its drafts
+	w = serveConceptIndex(r, "POST", "/__admin/concept-index/rebuild", `{}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"invalid_request"`)
+
+	w = serveConceptIndex(r, "POST", "/__admin/concept-index/rebuild", `{"contentUUIDs":["`+publishedContentUUID+`"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"indexed":0}`, w.Body.String())
+
+	w = serveConceptIndex(r, "GET", "/drafts/concepts/d7113d1d-ed66-3adf-9910-1f62b2c40e6a/content", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "rebuilding some contents does not complete the index")
+	assert.Contains(t, w.Body.String(), `"code":"concept_index_unavailable"`)
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Financial-Times/draft-annotations-api/concept"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// ConceptSearcher searches the concepts by label.
+type ConceptSearcher interface {
+	SearchConcepts(ctx context.Context, query string, types []string) ([]concept.Concept, error)
+}
+
+// WithConceptSearch serves the concept searches from s. Without it, the concept search is unavailable.
+func WithConceptSearch(s ConceptSearcher) Option {
+	return func(h *Handler) {
+		h.conceptSearch = s
+	}
+}
+
+// SearchConcepts returns the concepts matching the q query parameter, optionally of the given types.
+// Given a predicate, the concepts that cannot be annotated with it are left out, and the types default
+// to the ones the predicate is valid for.
+func (h *Handler) SearchConcepts(w http.ResponseWriter, r *http.Request) {
+	tID := tidutils.GetTransactionIDFromRequest(r)
+
+	ctx, cancel := context.WithTimeout(tidutils.TransactionAwareContext(r.Context(), tID), h.timeout)
+	defer cancel()
+
+	query := r.URL.Query()
+	q := strings.TrimSpace(query.Get("q"))
+	searchLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("query", q)
+
+	if q == "" {
+		writeSearchProblem(w, searchLog, &requestError{code: CodeInvalidRequest, err: errors.New("missing param q")})
+		return
+	}
+	predicate := query.Get("predicate")
+	if predicate != "" && !mapper.IsValidPACPredicate(predicate) {
+		writeSearchProblem(w, searchLog, &requestError{code: CodeInvalidPredicate, err: fmt.Errorf("invalid param predicate: %s", predicate)})
+		return
+	}
+	types := query["type"]
+	for _, t := range types {
+		if !strings.HasPrefix(t, conceptTypePrefix) || len(t) == len(conceptTypePrefix) {
+			writeSearchProblem(w, searchLog, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param type: %s", t)})
+			return
+		}
+	}
+	if len(types) == 0 && predicate != "" {
+		types = mapper.ConceptTypesForPredicate(predicate)
+	}
+	if h.conceptSearch == nil {
+		p := newProblem(errors.New("no concept search service configured"), http.StatusServiceUnavailable, CodeConceptSearchUnavailable, "No concept search service configured")
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+
+	searchLog.WithField("types", types).Info("Searching concepts")
+	found, err := h.conceptSearch.SearchConcepts(ctx, q, types)
+	if err != nil {
+		writeSearchProblem(w, searchLog, withUpstream(UpstreamConceptSearch, err))
+		return
+	}
+
+	concepts := make([]concept.Concept, 0, len(found))
+	for _, c := range found {
+		if predicate != "" && !mapper.IsValidConceptTypeForPredicate(predicate, c.Type) {
+			continue
+		}
+		c.ID = mapper.TransformConceptID(c.ID)
+		concepts = append(concepts, c)
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	response := concept.SearchResponse{Concepts: concepts}
+	if err := json.NewEncoder(w).Encode(&response); err != nil {
+		searchLog.WithError(err).Error("Failed to encode response")
+	}
+}
+
+func writeSearchProblem(w http.ResponseWriter, searchLog *log.Entry, err error) {
+	p := newProblem(err, http.StatusInternalServerError, CodeInternalError, fmt.Sprintf("Failed to search concepts: %v", err))
+	p.TransactionID = transactionID(searchLog)
+	if p.Code == CodeTimeout {
+		p.Detail = "Timeout while searching concepts"
+	}
+	searchLog.WithError(err).Error(p.Detail)
+	problem.Write(w, p)
+}
@@ -0,0 +1,99 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/concept"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+type conceptSearchStub struct {
+	concepts []concept.Concept
+	err      error
+	query    string
+	types    []string
+}
+
+func (s *conceptSearchStub) SearchConcepts(ctx context.Context, query string, types []string) ([]concept.Concept, error) {
+	s.query, s.types = query, types
+	return s.concepts, s.err
+}
+
+func serveConceptSearch(h *handler.Handler, rawQuery string) *httptest.ResponseRecorder {
+	r := vestigo.NewRouter()
+	r.Get("/drafts/concepts/search", h.SearchConcepts)
+	req := httptest.NewRequest("GET", "/drafts/concepts/search?"+rawQuery, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestSearchConcepts(t *testing.T) {
+	search := &conceptSearchStub{concepts: []concept.Concept{
+		{ID: "http://api.ft.com/things/5507ab98-b747-3ebc-b816-11603b9009f4", Type: "http://www.ft.com/ontology/Topic", PrefLabel: "Technology sector"},
+		{ID: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a", Type: "http://www.ft.com/ontology/person/Person", PrefLabel: "Technology Correspondent", IsFTAuthor: true},
+		{ID: "http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54", Type: "http://www.ft.com/ontology/product/Brand", PrefLabel: "Technology brand"},
+	}}
+	h := handler.New(&RWMock{}, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second,
+		handler.WithConceptSearch(search))
+
+	w := serveConceptSearch(h, "q=technology")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "technology", search.query)
+	assert.Empty(t, search.types)
+	assert.Contains(t, w.Body.String(), `"id":"http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4"`)
+	assert.Contains(t, w.Body.String(), `Technology brand`)
+
+	w = serveConceptSearch(h, "q=technology&predicate=http://www.ft.com/ontology/annotation/hasAuthor")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"http://www.ft.com/ontology/person/Person"}, search.types)
+	assert.JSONEq(t, `{"concepts":[{"id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a","type":"http://www.ft.com/ontology/person/Person","prefLabel":"Technology Correspondent","isFTAuthor":true}]}`, w.Body.String())
+
+	w = serveConceptSearch(h, "q=technology&predicate=http://www.ft.com/ontology/annotation/about&type=http://www.ft.com/ontology/Topic")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"http://www.ft.com/ontology/Topic"}, search.types)
+	assert.JSONEq(t, `{"concepts":[
+		{"id":"http://www.ft.com/thing/5507ab98-b747-3ebc-b816-11603b9009f4","type":"http://www.ft.com/ontology/Topic","prefLabel":"Technology sector"},
+		{"id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a","type":"http://www.ft.com/ontology/person/Person","prefLabel":"Technology Correspondent","isFTAuthor":true}
+	]}`, w.Body.String())
+}
+
+func TestSearchConceptsErrors(t *testing.T) {
+	c14n := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
+	h := handler.New(&RWMock{}, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second, handler.WithConceptSearch(&conceptSearchStub{}))
+
+	tests := map[string]struct {
+		query string
+		code  string
+	}{
+		"missing q":         {"q=%20", "invalid_request"},
+		"invalid predicate": {"q=tech&predicate=http://www.ft.com/ontology/annotation/invalid", "invalid_predicate"},
+		"invalid type":      {"q=tech&type=Topic", "invalid_request"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := serveConceptSearch(h, test.query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Contains(t, w.Body.String(), `"code":"`+test.code+`"`)
+		})
+	}
+
+	h = handler.New(&RWMock{}, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second)
+	w := serveConceptSearch(h, "q=tech")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"concept_search_unavailable"`)
+
+	h = handler.New(&RWMock{}, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second,
+		handler.WithConceptSearch(&conceptSearchStub{err: errors.New("computer says no")}))
+	w = serveConceptSearch(h, "q=tech")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"upstream":"concept-search-api"`)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	log "github.com/sirupsen/logrus"
+)
+
+// DegradedHeader tells that the annotations have been augmented with the last known concept data,
+// or not augmented at all, as the concepts API was unavailable.
+const DegradedHeader = "Annotations-Degraded"
+
+// WithDegradedReads serves the reads with the annotations augmented by fallback when the concepts API fails,
+// instead of failing them.
+func WithDegradedReads(fallback Augmenter) Option {
+	return func(h *Handler) {
+		h.degradedAugmenter = fallback
+	}
+}
+
+// WithDegradedWrites allows the writes to go on with the degraded augmentation when the concepts API fails.
+// It has no effect without WithDegradedReads.
+func WithDegradedWrites() Option {
+	return func(h *Handler) {
+		h.degradedWrites = true
+	}
+}
+
+// augment augments the annotations with the concept data. When the concepts API fails and degraded is allowed,
+// the annotations are augmented by the degraded augmenter instead, and the returned bool is true.
+func (h *Handler) augment(ctx context.Context, list []annotations.Annotation, degraded bool, logEntry *log.Entry) ([]annotations.Annotation, bool, error) {
+	augmented, err := h.annotationsAugmenter.AugmentAnnotations(ctx, list)
+	if err == nil {
+		return augmented, false, nil
+	}
+	if !degraded || h.degradedAugmenter == nil {
+		return nil, false, withUpstream(UpstreamConcepts, err)
+	}
+
+	logEntry.WithError(err).Warn("Concepts API unavailable, using the last known concept data")
+	augmented, degradedErr := h.degradedAugmenter.AugmentAnnotations(ctx, list)
+	if degradedErr != nil {
+		return nil, false, withUpstream(UpstreamConcepts, err)
+	}
+	return augmented, true, nil
+}
+
+// savedDraft is the outcome of saveAndReturnAnnotations.
+type savedDraft struct {
+	annotations *annotations.Annotations
+	hash        string
+	written     bool
+	degraded    bool
+	// dropped are the given annotations the augmenter dropped, as their concept was not found.
+	dropped []annotations.Annotation
+}
+
+func (d *savedDraft) writeHeaders(w http.ResponseWriter) {
+	w.Header().Set(annotations.DocumentHashHeader, d.hash)
+	w.Header().Set(DraftWrittenHeader, strconv.FormatBool(d.written))
+	if d.degraded {
+		w.Header().Set(DegradedHeader, "true")
+	}
+}
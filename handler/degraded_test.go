@@ -0,0 +1,161 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const degradedContentUUID = "83a201c6-60cd-11e7-91a7-502f7ee26895"
+
+var degradedDraft = []annotations.Annotation{
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/mentions",
+		ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+	},
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/about",
+		ConceptId: "http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb",
+	},
+}
+
+var failingAugmenter = &AugmenterMock{
+	augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+		return nil, errors.New("concepts API unavailable")
+	},
+}
+
+// lastKnownAugmenter knows the data of the first concept of degradedDraft only.
+var lastKnownAugmenter = &AugmenterMock{
+	augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+		augmented := make([]annotations.Annotation, 0, len(depletedAnnotations))
+		for _, ann := range depletedAnnotations {
+			if ann.ConceptId == degradedDraft[0].ConceptId {
+				ann.PrefLabel = "Barclays"
+				ann.Type = "http://www.ft.com/ontology/company/PublicCompany"
+			}
+			augmented = append(augmented, ann)
+		}
+		return augmented, nil
+	},
+}
+
+func TestReadAnnotationsDegraded(t *testing.T) {
+	tests := map[string]struct {
+		opts             []handler.Option
+		expectedStatus   int
+		expectedDegraded bool
+	}{
+		"degraded reads": {
+			opts:             []handler.Option{handler.WithDegradedReads(lastKnownAugmenter)},
+			expectedStatus:   http.StatusOK,
+			expectedDegraded: true,
+		},
+		"without degraded reads": {
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					return &annotations.Annotations{Annotations: degradedDraft}, "hash", true, nil
+				},
+			}
+			h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), failingAugmenter, time.Second, test.opts...)
+			r := vestigo.NewRouter()
+			r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+
+			req := httptest.NewRequest("GET", "/drafts/content/"+degradedContentUUID+"/annotations", nil)
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			if !test.expectedDegraded {
+				assert.Empty(t, w.Header().Get(handler.DegradedHeader))
+				return
+			}
+			assert.Equal(t, "true", w.Header().Get(handler.DegradedHeader))
+			assert.Equal(t, "hash", w.Header().Get(annotations.DocumentHashHeader))
+			assert.JSONEq(t, `{
+				"annotations": [
+					{
+						"predicate": "http://www.ft.com/ontology/annotation/mentions",
+						"id": "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+						"prefLabel": "Barclays",
+						"type": "http://www.ft.com/ontology/company/PublicCompany"
+					},
+					{
+						"predicate": "http://www.ft.com/ontology/annotation/about",
+						"id": "http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb"
+					}
+				],
+				"degraded": true
+			}`, w.Body.String())
+		})
+	}
+}
+
+func TestWriteAnnotationsDegraded(t *testing.T) {
+	tests := map[string]struct {
+		opts           []handler.Option
+		expectedStatus int
+		expectedWrite  bool
+	}{
+		"degraded writes": {
+			opts:           []handler.Option{handler.WithDegradedReads(lastKnownAugmenter), handler.WithDegradedWrites()},
+			expectedStatus: http.StatusOK,
+			expectedWrite:  true,
+		},
+		"writes blocked": {
+			opts:           []handler.Option{handler.WithDegradedReads(lastKnownAugmenter)},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var written *annotations.Annotations
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					return nil, "", false, nil
+				},
+				write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+					written = a
+					return "new-hash", nil
+				},
+			}
+			h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), failingAugmenter, time.Second, test.opts...)
+			r := vestigo.NewRouter()
+			r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+
+			body, _ := json.Marshal(&annotations.Annotations{Annotations: degradedDraft})
+			req := httptest.NewRequest("PUT", "/drafts/content/"+degradedContentUUID+"/annotations", strings.NewReader(string(body)))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, test.expectedStatus, w.Code)
+			if !test.expectedWrite {
+				assert.Nil(t, written)
+				return
+			}
+			assert.Equal(t, &annotations.Annotations{Annotations: []annotations.Annotation{degradedDraft[1], degradedDraft[0]}}, written)
+			assert.Equal(t, "true", w.Header().Get(handler.DegradedHeader))
+			assert.Equal(t, "new-hash", w.Header().Get(annotations.DocumentHashHeader))
+		})
+	}
+}
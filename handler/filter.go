@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+)
+
+const conceptTypePrefix = "http://www.ft.com/ontology/"
+
+// annotationFields are the JSON names of the annotation fields that can be selected with the fields query parameter.
+// The expanded concept data fields are only set with expand=concept.
+var annotationFields = map[string]struct{}{
+	"predicate":       {},
+	"id":              {},
+	"apiUrl":          {},
+	"type":            {},
+	"prefLabel":       {},
+	"isFTAuthor":      {},
+	"mergedFrom":      {},
+	"lifecycle":       {},
+	"aliases":         {},
+	"descriptionXML":  {},
+	"imageUrl":        {},
+	"broaderConcepts": {},
+	"isDeprecated":    {},
+}
+
+// expandConcept is the value of the expand query parameter returning the expanded concept data of the annotations.
+const expandConcept = "concept"
+
+// annotationsFilter selects the annotations returned by ReadAnnotations, and their fields.
+// Multiple values of the same filter match any of them, while different filters must all match.
+type annotationsFilter struct {
+	predicates map[string]struct{}
+	types      map[string]struct{}
+	isFTAuthor *bool
+	fields     []string
+	// expandConcepts keeps the expanded concept data of the annotations, left out otherwise.
+	expandConcepts bool
+}
+
+// parseAnnotationsFilter reads the predicate, type, isFTAuthor, fields and expand query parameters.
+func parseAnnotationsFilter(query url.Values) (*annotationsFilter, error) {
+	f := &annotationsFilter{}
+
+	for _, p := range query["predicate"] {
+		if !mapper.IsValidPACPredicate(p) {
+			return nil, &requestError{code: CodeInvalidPredicate, err: fmt.Errorf("invalid param predicate: %s", p)}
+		}
+		if f.predicates == nil {
+			f.predicates = make(map[string]struct{})
+		}
+		f.predicates[p] = struct{}{}
+	}
+
+	for _, t := range query["type"] {
+		if !strings.HasPrefix(t, conceptTypePrefix) || len(t) == len(conceptTypePrefix) {
+			return nil, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param type: %s", t)}
+		}
+		if f.types == nil {
+			f.types = make(map[string]struct{})
+		}
+		f.types[t] = struct{}{}
+	}
+
+	if v := query.Get("isFTAuthor"); v != "" {
+		isFTAuthor, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param isFTAuthor: %s", v)}
+		}
+		f.isFTAuthor = &isFTAuthor
+	}
+
+	if v := query.Get("fields"); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			field = strings.TrimSpace(field)
+			if _, found := annotationFields[field]; !found {
+				return nil, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param fields: unknown field %q", field)}
+			}
+			f.fields = append(f.fields, field)
+		}
+	}
+
+	if v := query.Get("expand"); v != "" {
+		if v != expandConcept {
+			return nil, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param expand: %s", v)}
+		}
+		f.expandConcepts = true
+	}
+
+	return f, nil
+}
+
+// matchesPredicate tells whether the annotation has any of the predicates filtered on.
+// Brands match hasBrand whether they are returned with hasBrand or, without sendHasBrand, with isClassifiedBy.
+func (f *annotationsFilter) matchesPredicate(ann annotations.Annotation) bool {
+	for p := range f.predicates {
+		if hasPredicate(ann, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply returns the annotations matching all the filters, without their expanded concept data unless asked for.
+func (f *annotationsFilter) apply(list []annotations.Annotation) []annotations.Annotation {
+	filtered := make([]annotations.Annotation, 0, len(list))
+	for _, ann := range list {
+		if f.predicates != nil && !f.matchesPredicate(ann) {
+			continue
+		}
+		if f.types != nil {
+			if _, found := f.types[ann.Type]; !found {
+				continue
+			}
+		}
+		if f.isFTAuthor != nil && ann.IsFTAuthor != *f.isFTAuthor {
+			continue
+		}
+		if !f.expandConcepts {
+			ann.ConceptExpansion = nil
+		}
+		filtered = append(filtered, ann)
+	}
+	return filtered
+}
+
+// project returns the annotations with only the selected fields, or the annotations as they are if no field is selected.
+func (f *annotationsFilter) project(list []annotations.Annotation) (interface{}, error) {
+	if len(f.fields) == 0 {
+		return list, nil
+	}
+
+	projected := make([]map[string]interface{}, 0, len(list))
+	for _, ann := range list {
+		b, err := json.Marshal(ann)
+		if err != nil {
+			return nil, err
+		}
+		var all map[string]interface{}
+		if err := json.Unmarshal(b, &all); err != nil {
+			return nil, err
+		}
+		selected := make(map[string]interface{}, len(f.fields))
+		for _, field := range f.fields {
+			if v, found := all[field]; found {
+				selected[field] = v
+			}
+		}
+		projected = append(projected, selected)
+	}
+	return projected, nil
+}
@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/concept"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+var filterDraft = []annotations.Annotation{
+	{
+		Predicate:  "http://www.ft.com/ontology/annotation/hasAuthor",
+		ConceptId:  "http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b",
+		ApiUrl:     "http://api.ft.com/people/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b",
+		Type:       "http://www.ft.com/ontology/person/Person",
+		PrefLabel:  "Lisa Barrett",
+		IsFTAuthor: true,
+	},
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/mentions",
+		ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+		ApiUrl:    "http://api.ft.com/people/0a619d71-9af5-3755-90dd-f789b686c67a",
+		Type:      "http://www.ft.com/ontology/person/Person",
+		PrefLabel: "Barack H. Obama",
+	},
+	{
+		Predicate: "http://www.ft.com/ontology/hasBrand",
+		ConceptId: "http://www.ft.com/thing/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed",
+		ApiUrl:    "http://api.ft.com/things/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed",
+		Type:      "http://www.ft.com/ontology/product/Brand",
+		PrefLabel: "Lex",
+		ConceptExpansion: &annotations.ConceptExpansion{
+			Aliases:        []string{"Lex column"},
+			DescriptionXML: "<p>The FT's agenda-setting column on investment.</p>",
+			BroaderConcepts: []concept.BroaderConcept{
+				{ID: "http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54", PrefLabel: "Financial Times"},
+			},
+		},
+	},
+}
+
+func TestReadAnnotationsFilters(t *testing.T) {
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return &annotations.Annotations{Annotations: filterDraft}, "hash", true, nil
+		},
+	}
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			return depletedAnnotations, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, nil, aug, time.Second)
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+
+	tests := map[string]struct {
+		query          string
+		expectedStatus int
+		expectedCode   string
+		expectedBody   string
+	}{
+		"predicate": {
+			query:          "predicate=http://www.ft.com/ontology/annotation/hasAuthor",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/hasAuthor","id":"http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b","apiUrl":"http://api.ft.com/people/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b","type":"http://www.ft.com/ontology/person/Person","prefLabel":"Lisa Barrett","isFTAuthor":true}]}`,
+		},
+		"multiple predicates": {
+			query:          "predicate=http://www.ft.com/ontology/annotation/hasAuthor&predicate=http://www.ft.com/ontology/annotation/mentions&fields=id",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"id":"http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b"},{"id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}]}`,
+		},
+		"brand type after switching to isClassifiedBy": {
+			query:          "type=http://www.ft.com/ontology/product/Brand&fields=predicate,prefLabel",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"predicate":"http://www.ft.com/ontology/classification/isClassifiedBy","prefLabel":"Lex"}]}`,
+		},
+		"brand predicate with sendHasBrand": {
+			query:          "sendHasBrand=true&predicate=http://www.ft.com/ontology/hasBrand&fields=prefLabel",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"prefLabel":"Lex"}]}`,
+		},
+		"brand predicate without sendHasBrand": {
+			query:          "predicate=http://www.ft.com/ontology/hasBrand&fields=predicate,prefLabel",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"predicate":"http://www.ft.com/ontology/classification/isClassifiedBy","prefLabel":"Lex"}]}`,
+		},
+		"isClassifiedBy predicate with sendHasBrand": {
+			query:          "sendHasBrand=true&predicate=http://www.ft.com/ontology/classification/isClassifiedBy",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[]}`,
+		},
+		"isFTAuthor and type": {
+			query:          "isFTAuthor=false&type=http://www.ft.com/ontology/person/Person&fields=prefLabel,isFTAuthor",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"prefLabel":"Barack H. Obama"}]}`,
+		},
+		"concept expansion left out by default": {
+			query:          "type=http://www.ft.com/ontology/product/Brand",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"predicate":"http://www.ft.com/ontology/classification/isClassifiedBy","id":"http://www.ft.com/thing/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed","apiUrl":"http://api.ft.com/things/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed","type":"http://www.ft.com/ontology/product/Brand","prefLabel":"Lex"}]}`,
+		},
+		"expand concept": {
+			query:          "type=http://www.ft.com/ontology/product/Brand&expand=concept",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"predicate":"http://www.ft.com/ontology/classification/isClassifiedBy","id":"http://www.ft.com/thing/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed","apiUrl":"http://api.ft.com/things/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed","type":"http://www.ft.com/ontology/product/Brand","prefLabel":"Lex","aliases":["Lex column"],"descriptionXML":"<p>The FT's agenda-setting column on investment.</p>","broaderConcepts":[{"id":"http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54","prefLabel":"Financial Times"}]}]}`,
+		},
+		"expand concept with fields": {
+			query:          "expand=concept&fields=prefLabel,aliases",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[{"prefLabel":"Lisa Barrett"},{"prefLabel":"Barack H. Obama"},{"prefLabel":"Lex","aliases":["Lex column"]}]}`,
+		},
+		"no match": {
+			query:          "type=http://www.ft.com/ontology/Topic",
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"annotations":[]}`,
+		},
+		"invalid predicate": {
+			query:          "predicate=http://www.ft.com/ontology/implicitlyAbout",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidPredicate,
+		},
+		"invalid type": {
+			query:          "type=Brand",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidRequest,
+		},
+		"invalid isFTAuthor": {
+			query:          "isFTAuthor=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidRequest,
+		},
+		"invalid expand": {
+			query:          "expand=concept,everything",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidRequest,
+		},
+		"unknown field": {
+			query:          "fields=id,types",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidRequest,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations?"+test.query, nil)
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, test.expectedStatus, w.Code)
+
+			if test.expectedStatus == http.StatusOK {
+				assert.JSONEq(t, test.expectedBody, w.Body.String())
+				assert.Equal(t, "hash", w.Header().Get(annotations.DocumentHashHeader))
+				return
+			}
+			var problem handler.Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, test.expectedCode, problem.Code)
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+)
+
+// Media types ReadAnnotations can respond with.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeJSONLD   = "application/ld+json"
+	ContentTypeNTriples = "application/n-triples"
+	ContentTypeCSV      = "text/csv"
+)
+
+const (
+	thingURIPrefix = "http://www.ft.com/thing/"
+	skosPrefLabel  = "http://www.w3.org/2004/02/skos/core#prefLabel"
+)
+
+// Values of the format query param of ReadAnnotations.
+const (
+	formatPAC = "pac"
+	formatUPP = "upp"
+)
+
+// offeredContentTypes are the media types ReadAnnotations can respond with, by order of preference.
+var offeredContentTypes = []string{ContentTypeJSON, ContentTypeJSONLD, ContentTypeNTriples, ContentTypeCSV}
+
+// csvColumns are the default columns of the CSV output, in the order of the JSON fields.
+var csvColumns = []string{"predicate", "id", "apiUrl", "type", "prefLabel", "isFTAuthor", "mergedFrom"}
+
+// csvListSeparator joins the values of the list fields in a CSV column, e.g. the aliases or the IDs of the broader concepts.
+const csvListSeparator = "|"
+
+// jsonLDPredicates are the terms of the PAC predicates in the JSON-LD @context.
+var jsonLDPredicates = []string{
+	mapper.PredicateAbout,
+	mapper.PredicateHasAuthor,
+	mapper.PredicateHasBrand,
+	mapper.PredicateHasContributor,
+	mapper.PredicateHasDisplayTag,
+	mapper.PredicateIsClassifiedBy,
+	mapper.PredicateMentions,
+}
+
+// negotiateContentType returns the offered media type that best matches the Accept header.
+// JSON is returned when the header is missing or accepts none of the offered media types,
+// and false only when it explicitly excludes JSON, e.g. with application/json;q=0.
+func negotiateContentType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return ContentTypeJSON, true
+	}
+
+	best, bestQ := "", 0.0
+	for _, offered := range offeredContentTypes {
+		if q, _ := acceptQuality(accept, offered); q > bestQ {
+			best, bestQ = offered, q
+		}
+	}
+	if bestQ > 0 {
+		return best, true
+	}
+	if _, matched := acceptQuality(accept, ContentTypeJSON); matched {
+		return "", false
+	}
+	return ContentTypeJSON, true
+}
+
+// acceptQuality returns the quality the Accept header gives to the media type, using its most specific matching range,
+// and whether any range matches the media type at all.
+func acceptQuality(accept string, mediaType string) (float64, bool) {
+	q, specificity := 0.0, -1
+	for _, r := range strings.Split(accept, ",") {
+		params := strings.Split(r, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+
+		s := -1
+		switch {
+		case mediaRange == mediaType:
+			s = 2
+		case strings.HasSuffix(mediaRange, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(mediaRange, "*")):
+			s = 1
+		case mediaRange == "*/*":
+			s = 0
+		}
+		if s <= specificity {
+			continue
+		}
+
+		rangeQ := 1.0
+		for _, p := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 && strings.TrimSpace(kv[0]) == "q" {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(kv[1]), 64); err == nil {
+					rangeQ = v
+				}
+			}
+		}
+		q, specificity = rangeQ, s
+	}
+	return q, specificity >= 0
+}
+
+// writeAnnotations encodes the annotations of the given content in the negotiated media type.
+// The fields selection applies to the JSON and CSV outputs only, as the RDF outputs have a fixed shape.
+// Degraded annotations are flagged in the JSON output only, the other outputs rely on the DegradedHeader.
+func writeAnnotations(w io.Writer, contentType string, contentUUID string, list []annotations.Annotation, filter *annotationsFilter, degraded bool) error {
+	switch contentType {
+	case ContentTypeJSONLD:
+		return writeJSONLD(w, contentUUID, list)
+	case ContentTypeNTriples:
+		return writeNTriples(w, contentUUID, list)
+	case ContentTypeCSV:
+		return writeCSV(w, list, filter.fields)
+	default:
+		projected, err := filter.project(list)
+		if err != nil {
+			return err
+		}
+		response := struct {
+			Annotations interface{} `json:"annotations"`
+			Degraded    bool        `json:"degraded,omitempty"`
+		}{Annotations: projected, Degraded: degraded}
+		return json.NewEncoder(w).Encode(&response)
+	}
+}
+
+// parseFormat validates the format query param against the negotiated media type.
+// The UPP format is a JSON shape, so it cannot be combined with the other media types.
+func parseFormat(format string, contentType string) (string, error) {
+	switch format {
+	case "", formatPAC:
+		return formatPAC, nil
+	case formatUPP:
+		if contentType != ContentTypeJSON {
+			return "", &requestError{code: CodeInvalidRequest, err: fmt.Errorf("format %s is only available as %s", formatUPP, ContentTypeJSON)}
+		}
+		return formatUPP, nil
+	default:
+		return "", &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param format: %s", format)}
+	}
+}
+
+// writeUPPAnnotations encodes the annotations as the UPP annotations API would return them.
+// The fields selection does not apply, as UPP annotations have a fixed shape.
+func writeUPPAnnotations(w io.Writer, list []annotations.Annotation, degraded bool) error {
+	body, err := json.Marshal(list)
+	if err != nil {
+		return err
+	}
+	body, err = mapper.ConvertToUPP(body)
+	if err != nil {
+		return err
+	}
+	response := struct {
+		Annotations json.RawMessage `json:"annotations"`
+		Degraded    bool            `json:"degraded,omitempty"`
+	}{Annotations: body, Degraded: degraded}
+	return json.NewEncoder(w).Encode(&response)
+}
+
+func jsonLDContext() map[string]interface{} {
+	context := map[string]interface{}{
+		"id":        "@id",
+		"type":      "@type",
+		"prefLabel": skosPrefLabel,
+	}
+	for _, p := range jsonLDPredicates {
+		context[jsonLDTerm(p)] = p
+	}
+	return context
+}
+
+// jsonLDTerm returns the term of a PAC predicate, e.g. about for http://www.ft.com/ontology/annotation/about.
+func jsonLDTerm(predicate string) string {
+	return predicate[strings.LastIndex(predicate, "/")+1:]
+}
+
+func writeJSONLD(w io.Writer, contentUUID string, list []annotations.Annotation) error {
+	terms := make(map[string]string, len(jsonLDPredicates))
+	for _, p := range jsonLDPredicates {
+		terms[p] = jsonLDTerm(p)
+	}
+
+	doc := map[string]interface{}{
+		"@context": jsonLDContext(),
+		"id":       thingURIPrefix + contentUUID,
+	}
+	for _, ann := range list {
+		// predicates without a term in the @context are expanded by JSON-LD processors as they are absolute IRIs
+		key, found := terms[ann.Predicate]
+		if !found {
+			key = ann.Predicate
+		}
+		concept := map[string]interface{}{"id": ann.ConceptId}
+		if ann.Type != "" {
+			concept["type"] = ann.Type
+		}
+		if ann.PrefLabel != "" {
+			concept["prefLabel"] = ann.PrefLabel
+		}
+		concepts, _ := doc[key].([]interface{})
+		doc[key] = append(concepts, concept)
+	}
+	return json.NewEncoder(w).Encode(doc)
+}
+
+func writeNTriples(w io.Writer, contentUUID string, list []annotations.Annotation) error {
+	bw := bufio.NewWriter(w)
+	for _, ann := range list {
+		if _, err := fmt.Fprintf(bw, "<%s%s> <%s> <%s> .\n", thingURIPrefix, contentUUID, ann.Predicate, ann.ConceptId); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func writeCSV(w io.Writer, list []annotations.Annotation, fields []string) error {
+	columns := fields
+	if len(columns) == 0 {
+		columns = csvColumns
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, ann := range list {
+		values := map[string]string{
+			"predicate":  ann.Predicate,
+			"id":         ann.ConceptId,
+			"apiUrl":     ann.ApiUrl,
+			"type":       ann.Type,
+			"prefLabel":  ann.PrefLabel,
+			"isFTAuthor": strconv.FormatBool(ann.IsFTAuthor),
+			"mergedFrom": ann.MergedFrom,
+			"lifecycle":  ann.Lifecycle,
+		}
+		if e := ann.ConceptExpansion; e != nil {
+			broader := make([]string, 0, len(e.BroaderConcepts))
+			for _, b := range e.BroaderConcepts {
+				broader = append(broader, b.ID)
+			}
+			values["aliases"] = strings.Join(e.Aliases, csvListSeparator)
+			values["descriptionXML"] = e.DescriptionXML
+			values["imageUrl"] = e.ImageURL
+			values["broaderConcepts"] = strings.Join(broader, csvListSeparator)
+			values["isDeprecated"] = strconv.FormatBool(e.IsDeprecated)
+		}
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = values[c]
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func notAcceptableProblem(accept string, tID string) *Problem {
+	p := newProblem(nil, http.StatusNotAcceptable, CodeNotAcceptable,
+		fmt.Sprintf("none of the accepted media types %q is available, use one of %s", accept, strings.Join(offeredContentTypes, ", ")))
+	p.TransactionID = tID
+	return p
+}
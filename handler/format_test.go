@@ -0,0 +1,235 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const formatContentUUID = "83a201c6-60cd-11e7-91a7-502f7ee26895"
+
+func newFormatRouter() *vestigo.Router {
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return &annotations.Annotations{Annotations: filterDraft[:2]}, "hash", true, nil
+		},
+	}
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			return depletedAnnotations, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, nil, aug, time.Second)
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+	return r
+}
+
+func TestReadAnnotationsContentNegotiation(t *testing.T) {
+	r := newFormatRouter()
+
+	tests := map[string]struct {
+		accept              string
+		query               string
+		expectedStatus      int
+		expectedContentType string
+		expectedBody        string
+	}{
+		"default": {
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeJSON,
+		},
+		"any": {
+			accept:              "*/*",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeJSON,
+		},
+		"n-triples": {
+			accept:              "application/n-triples",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeNTriples,
+			expectedBody: "<http://www.ft.com/thing/83a201c6-60cd-11e7-91a7-502f7ee26895> <http://www.ft.com/ontology/annotation/hasAuthor> <http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b> .\n" +
+				"<http://www.ft.com/thing/83a201c6-60cd-11e7-91a7-502f7ee26895> <http://www.ft.com/ontology/annotation/mentions> <http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a> .\n",
+		},
+		"csv": {
+			accept:              "text/csv",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeCSV,
+			expectedBody: "predicate,id,apiUrl,type,prefLabel,isFTAuthor,mergedFrom\n" +
+				"http://www.ft.com/ontology/annotation/hasAuthor,http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b,http://api.ft.com/people/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b,http://www.ft.com/ontology/person/Person,Lisa Barrett,true,\n" +
+				"http://www.ft.com/ontology/annotation/mentions,http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a,http://api.ft.com/people/0a619d71-9af5-3755-90dd-f789b686c67a,http://www.ft.com/ontology/person/Person,Barack H. Obama,false,\n",
+		},
+		"csv with fields and filters": {
+			accept:              "text/csv",
+			query:               "?isFTAuthor=true&fields=prefLabel,id",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeCSV,
+			expectedBody:        "prefLabel,id\nLisa Barrett,http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b\n",
+		},
+		"quality values": {
+			accept:              "application/json;q=0.5, text/csv;q=0.4, application/n-triples",
+			query:               "?isFTAuthor=false",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeNTriples,
+			expectedBody:        "<http://www.ft.com/thing/83a201c6-60cd-11e7-91a7-502f7ee26895> <http://www.ft.com/ontology/annotation/mentions> <http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a> .\n",
+		},
+		"excluded by quality": {
+			accept:              "text/*;q=0, application/xml",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeJSON,
+		},
+		"unknown media type": {
+			accept:              "application/xml",
+			expectedStatus:      http.StatusOK,
+			expectedContentType: handler.ContentTypeJSON,
+		},
+		"json excluded": {
+			accept:         "application/json;q=0",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		"json excluded with unknown media type": {
+			accept:         "text/html, application/json;q=0",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+		"everything excluded": {
+			accept:         "*/*;q=0",
+			expectedStatus: http.StatusNotAcceptable,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/drafts/content/"+formatContentUUID+"/annotations"+test.query, nil)
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, test.expectedStatus, w.Code)
+
+			if test.expectedStatus != http.StatusOK {
+				assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+				var p handler.Problem
+				assert.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+				assert.Equal(t, handler.CodeNotAcceptable, p.Code)
+				return
+			}
+			assert.Equal(t, test.expectedContentType, w.Header().Get("Content-Type"))
+			if test.expectedBody != "" {
+				assert.Equal(t, test.expectedBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestReadAnnotationsJSONLD(t *testing.T) {
+	r := newFormatRouter()
+
+	req := httptest.NewRequest("GET", "/drafts/content/"+formatContentUUID+"/annotations", nil)
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	req.Header.Set("Accept", "application/ld+json")
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handler.ContentTypeJSONLD, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"@context": {
+			"id": "@id",
+			"type": "@type",
+			"prefLabel": "http://www.w3.org/2004/02/skos/core#prefLabel",
+			"about": "http://www.ft.com/ontology/annotation/about",
+			"hasAuthor": "http://www.ft.com/ontology/annotation/hasAuthor",
+			"hasBrand": "http://www.ft.com/ontology/hasBrand",
+			"hasContributor": "http://www.ft.com/ontology/hasContributor",
+			"hasDisplayTag": "http://www.ft.com/ontology/hasDisplayTag",
+			"isClassifiedBy": "http://www.ft.com/ontology/classification/isClassifiedBy",
+			"mentions": "http://www.ft.com/ontology/annotation/mentions"
+		},
+		"id": "http://www.ft.com/thing/83a201c6-60cd-11e7-91a7-502f7ee26895",
+		"hasAuthor": [
+			{
+				"id": "http://www.ft.com/thing/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b",
+				"type": "http://www.ft.com/ontology/person/Person",
+				"prefLabel": "Lisa Barrett"
+			}
+		],
+		"mentions": [
+			{
+				"id": "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+				"type": "http://www.ft.com/ontology/person/Person",
+				"prefLabel": "Barack H. Obama"
+			}
+		]
+	}`, w.Body.String())
+}
+
+func TestReadAnnotationsUPPFormat(t *testing.T) {
+	r := newFormatRouter()
+
+	req := httptest.NewRequest("GET", "/drafts/content/"+formatContentUUID+"/annotations?format=upp&isFTAuthor=true", nil)
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handler.ContentTypeJSON, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"annotations": [
+			{
+				"predicate": "http://www.ft.com/ontology/annotation/hasAuthor",
+				"id": "http://api.ft.com/things/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b",
+				"apiUrl": "http://api.ft.com/people/5bd49568-6d7c-3c10-a5b0-2f3fd5974a6b",
+				"types": [
+					"http://www.ft.com/ontology/core/Thing",
+					"http://www.ft.com/ontology/concept/Concept",
+					"http://www.ft.com/ontology/person/Person"
+				],
+				"prefLabel": "Lisa Barrett",
+				"isFTAuthor": true
+			}
+		]
+	}`, w.Body.String())
+}
+
+func TestReadAnnotationsInvalidFormat(t *testing.T) {
+	r := newFormatRouter()
+
+	tests := map[string]struct {
+		accept string
+		query  string
+	}{
+		"unknown format": {query: "?format=v1"},
+		"upp as csv":     {query: "?format=upp", accept: "text/csv"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/drafts/content/"+formatContentUUID+"/annotations"+test.query, nil)
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var p handler.Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+			assert.Equal(t, handler.CodeInvalidRequest, p.Code)
+		})
+	}
+}
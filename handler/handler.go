@@ -13,16 +13,23 @@ import (
 
 	"github.com/Financial-Times/draft-annotations-api/annotations"
 	"github.com/Financial-Times/draft-annotations-api/mapper"
+	"github.com/Financial-Times/draft-annotations-api/problem"
 	tidutils "github.com/Financial-Times/transactionid-utils-go"
 	"github.com/husobee/vestigo"
+	metrics "github.com/rcrowley/go-metrics"
 	uuid "github.com/satori/go.uuid"
 	log "github.com/sirupsen/logrus"
 )
 
+// DraftWrittenHeader tells whether a write request changed the draft annotations,
+// which are not written again when they are canonically unchanged.
+const DraftWrittenHeader = "Draft-Written"
+
 // AnnotationsAPI interface encapsulates logic for getting published annotations from API
 type AnnotationsAPI interface {
 	GetAll(context.Context, string) ([]annotations.Annotation, error)
-	GetAllButV2(context.Context, string) ([]annotations.Annotation, error)
+	GetEditable(context.Context, string) ([]annotations.Annotation, error)
+	GetByLifecycles(context.Context, string, []string) ([]annotations.Annotation, error)
 }
 
 // Interface for the annotations augmenter (currently only functionality in the annotations package)
@@ -37,21 +44,62 @@ type Handler struct {
 	c14n                 *annotations.Canonicalizer
 	annotationsAugmenter Augmenter
 	timeout              time.Duration
+
+	migrateMergedConcepts bool
+	strictWrites          bool
+	predicatePrecedence   []string
+	degradedAugmenter     Augmenter
+	degradedWrites        bool
+	readCacheTTL          time.Duration
+	metrics               metrics.Registry
+	reads                 *readCoalescer
+	machineLifecycles     []string
+	suggester             Suggester
+	conceptSearch         ConceptSearcher
+	conceptIndex          annotations.ConceptIndex
 }
 
-// New initializes Handler.
-func New(rw annotations.RW, annotationsAPI AnnotationsAPI, c14n *annotations.Canonicalizer, augmenter Augmenter, httpTimeout time.Duration) *Handler {
-	return &Handler{
-		rw,
-		annotationsAPI,
-		c14n,
-		augmenter,
-		httpTimeout,
+// Option configures optional behaviour of the Handler.
+type Option func(*Handler)
+
+// WithMergedConceptsMigration enables rewriting stored drafts to the canonical concept IDs
+// when a read finds annotations for concepts that have been merged or superseded.
+func WithMergedConceptsMigration() Option {
+	return func(h *Handler) {
+		h.migrateMergedConcepts = true
 	}
 }
 
+// WithStrictWrites makes WriteAnnotations reject bodies with invalid predicates or concept IDs,
+// as if every request asked for it with strict=true.
+func WithStrictWrites() Option {
+	return func(h *Handler) {
+		h.strictWrites = true
+	}
+}
+
+// New initializes Handler.
+func New(rw annotations.RW, annotationsAPI AnnotationsAPI, c14n *annotations.Canonicalizer, augmenter Augmenter, httpTimeout time.Duration, opts ...Option) *Handler {
+	h := &Handler{
+		annotationsRW:        rw,
+		annotationsAPI:       annotationsAPI,
+		c14n:                 c14n,
+		annotationsAugmenter: augmenter,
+		timeout:              httpTimeout,
+		predicatePrecedence:  DefaultPredicatePrecedence,
+		metrics:              metrics.NewRegistry(),
+		machineLifecycles:    DefaultMachineLifecycles,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	h.reads = newReadCoalescer(h.readCacheTTL, h.metrics)
+	return h
+}
+
 // DeleteAnnotation deletes a given annotation for a given content uuid.
-// It gets the annotations only from UPP skipping V2 annotations because they are not editorially curated.
+// All the annotations of the concept are deleted, unless the predicate query parameter selects the one to delete.
+// It gets only the editable annotations from UPP, as the others are not editorially curated.
 func (h *Handler) DeleteAnnotation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
@@ -64,16 +112,22 @@ func (h *Handler) DeleteAnnotation(w http.ResponseWriter, r *http.Request) {
 
 	oldHash := r.Header.Get(annotations.PreviousDocumentHashHeader)
 
+	predicate := r.URL.Query().Get("predicate")
+	if predicate != "" && !mapper.IsValidPACPredicate(predicate) {
+		handleWriteErrors("Invalid request", CodeInvalidPredicate, errors.New("invalid predicate"), writeLog, w, http.StatusBadRequest)
+		return
+	}
+
 	writeLog.Debug("Validating input and reading annotations from UPP...")
-	uppList, httpStatus, err := h.prepareUPPAnnotations(ctx, contentUUID, conceptID)
+	uppList, publishedCached, err := h.prepareUPPAnnotations(ctx, contentUUID, conceptID)
 	if err != nil {
-		handleWriteErrors("Error while preparing annotations", err, writeLog, w, httpStatus)
+		handleWriteErrors("Error while preparing annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
 	i := 0
 	for _, item := range uppList {
-		if item.ConceptId == conceptID {
+		if item.ConceptId == conceptID && (predicate == "" || hasPredicate(item, predicate)) {
 			continue
 		}
 		uppList[i] = item
@@ -81,17 +135,23 @@ func (h *Handler) DeleteAnnotation(w http.ResponseWriter, r *http.Request) {
 	}
 	uppList = uppList[:i]
 
-	_, newHash, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
+	saved, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
 	if err != nil {
-		handleWriteErrors("Error writing draft annotations", err, writeLog, w, http.StatusInternalServerError)
+		handleWriteErrors("Error writing draft annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set(annotations.DocumentHashHeader, newHash)
+	saved.writeHeaders(w)
+	setPublishedCachedHeader(w, publishedCached)
+
+	err = json.NewEncoder(w).Encode(saved.annotations)
+	if err != nil {
+		handleWriteErrors("Error in encoding draft annotations response", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
+	}
 }
 
 // AddAnnotation adds an annotation for a specific content uuid.
-// It gets the annotations only from UPP skipping V2 annotations because they are not editorially curated.
+// It gets only the editable annotations from UPP, as the others are not editorially curated.
 func (h *Handler) AddAnnotation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
@@ -106,19 +166,19 @@ func (h *Handler) AddAnnotation(w http.ResponseWriter, r *http.Request) {
 	addedAnnotation := annotations.Annotation{}
 	err := json.NewDecoder(r.Body).Decode(&addedAnnotation)
 	if err != nil {
-		handleWriteErrors("Error decoding request body", err, writeLog, w, http.StatusBadRequest)
+		handleWriteErrors("Error decoding request body", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
 		return
 	}
 
 	if !mapper.IsValidPACPredicate(addedAnnotation.Predicate) {
-		handleWriteErrors("Invalid request", errors.New("invalid predicate"), writeLog, w, http.StatusBadRequest)
+		handleWriteErrors("Invalid request", CodeInvalidPredicate, errors.New("invalid predicate"), writeLog, w, http.StatusBadRequest)
 		return
 	}
 
 	writeLog.Debug("Validating input and reading annotations from UPP...")
-	uppList, httpStatus, err := h.prepareUPPAnnotations(ctx, contentUUID, addedAnnotation.ConceptId)
+	uppList, publishedCached, err := h.prepareUPPAnnotations(ctx, contentUUID, addedAnnotation.ConceptId)
 	if err != nil {
-		handleWriteErrors("Error while preparing annotations", err, writeLog, w, httpStatus)
+		handleWriteErrors("Error while preparing annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
@@ -134,17 +194,22 @@ func (h *Handler) AddAnnotation(w http.ResponseWriter, r *http.Request) {
 		uppList = append(uppList, addedAnnotation)
 	}
 
-	_, newHash, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
+	saved, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
 	if err != nil {
-		handleWriteErrors("Error writing draft annotations", err, writeLog, w, http.StatusInternalServerError)
+		handleWriteErrors("Error writing draft annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set(annotations.DocumentHashHeader, newHash)
+	saved.writeHeaders(w)
+	setPublishedCachedHeader(w, publishedCached)
 }
 
 // ReadAnnotations gets the annotations for a given content uuid.
 // If there are draft annotations, they are returned, otherwise the published annotations are returned.
+// The lifecycle query parameter asks for the published annotations of the given lifecycles instead, whether there is a draft or not.
+// The sections query parameter returns the machine annotations apart from the editorial ones.
+// The returned annotations and their fields can be selected with the predicate, type, isFTAuthor and fields query parameters.
+// The response is JSON by default, or JSON-LD, N-Triples or CSV depending on the Accept header.
 func (h *Handler) ReadAnnotations(w http.ResponseWriter, r *http.Request) {
 	contentUUID := vestigo.Param(r, "uuid")
 	tID := tidutils.GetTransactionIDFromRequest(r)
@@ -154,7 +219,12 @@ func (h *Handler) ReadAnnotations(w http.ResponseWriter, r *http.Request) {
 
 	readLog := readLogEntry(ctx, contentUUID)
 
-	w.Header().Add("Content-Type", "application/json")
+	contentType, acceptable := negotiateContentType(r.Header.Get("Accept"))
+	if !acceptable {
+		problem.Write(w, notAcceptableProblem(r.Header.Get("Accept"), tID))
+		return
+	}
+	w.Header().Add("Content-Type", contentType)
 
 	showHasBrand := false
 	var err error
@@ -162,22 +232,71 @@ func (h *Handler) ReadAnnotations(w http.ResponseWriter, r *http.Request) {
 	if queryParam != "" {
 		showHasBrand, err = strconv.ParseBool(queryParam)
 		if err != nil {
-			writeMessage(w, fmt.Sprintf("invalid param sendHasBrand: %s ", queryParam), http.StatusBadRequest)
+			p := newProblem(err, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid param sendHasBrand: %s ", queryParam))
+			p.TransactionID = tID
+			problem.Write(w, p)
 			return
 		}
 	}
 
-	result, hash, err := h.readAnnotations(ctx, contentUUID, showHasBrand, readLog)
+	filter, err := parseAnnotationsFilter(r.URL.Query())
+	if err != nil {
+		p := newProblem(err, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+
+	format, err := parseFormat(r.URL.Query().Get("format"), contentType)
+	if err != nil {
+		p := newProblem(err, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+
+	sections := false
+	if v := r.URL.Query().Get("sections"); v != "" {
+		sections, err = strconv.ParseBool(v)
+		if err == nil && sections && (format != formatPAC || contentType != ContentTypeJSON || len(r.URL.Query()["lifecycle"]) > 0) {
+			err = fmt.Errorf("sections are only available as %s, without format or lifecycle", ContentTypeJSON)
+		}
+		if err != nil {
+			p := newProblem(err, http.StatusBadRequest, CodeInvalidRequest, fmt.Sprintf("invalid param sections: %s", err.Error()))
+			p.TransactionID = tID
+			problem.Write(w, p)
+			return
+		}
+	}
+	if sections {
+		h.readAnnotationSections(ctx, w, contentUUID, showHasBrand, filter, readLog)
+		return
+	}
+
+	var result readResult
+	if lifecycles := r.URL.Query()["lifecycle"]; len(lifecycles) > 0 {
+		result, err = h.readPublishedAnnotations(ctx, contentUUID, lifecycles, showHasBrand, readLog)
+	} else {
+		result, err = h.coalescedReadAnnotations(ctx, contentUUID, showHasBrand, readLog)
+	}
 	if err != nil {
 		handleReadErrors(err, readLog, w)
 		return
 	}
-	if hash != "" {
-		w.Header().Set(annotations.DocumentHashHeader, hash)
+	if result.hash != "" {
+		w.Header().Set(annotations.DocumentHashHeader, result.hash)
 	}
+	if result.degraded {
+		w.Header().Set(DegradedHeader, "true")
+	}
+	setPublishedCachedHeader(w, result.publishedCached)
+	setMissingLifecyclesHeader(w, result.missingLifecycles)
 
-	response := annotations.Annotations{Annotations: result}
-	err = json.NewEncoder(w).Encode(&response)
+	if format == formatUPP {
+		err = writeUPPAnnotations(w, filter.apply(result.annotations), result.degraded)
+	} else {
+		err = writeAnnotations(w, contentType, contentUUID, filter.apply(result.annotations), filter, result.degraded)
+	}
 	if err != nil {
 		readLog.WithError(err).Error("Failed to encode response")
 		handleReadErrors(err, readLog, w)
@@ -197,34 +316,63 @@ func (h *Handler) WriteAnnotations(w http.ResponseWriter, r *http.Request) {
 	writeLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("uuid", contentUUID)
 
 	if err := validateUUID(contentUUID); err != nil {
-		handleWriteErrors("Invalid content UUID", err, writeLog, w, http.StatusBadRequest)
+		handleWriteErrors("Invalid content UUID", CodeInvalidContentUUID, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+
+	uppPayload, err := isUPPPayload(r)
+	if err != nil {
+		handleWriteErrors("Invalid annotations format", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
 		return
 	}
 
 	var draftAnnotations annotations.Annotations
-	err := json.NewDecoder(r.Body).Decode(&draftAnnotations)
+	var conversions []mapper.Conversion
+	if uppPayload {
+		draftAnnotations.Annotations, conversions, err = decodeUPPAnnotations(r.Body)
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&draftAnnotations)
+	}
+	if err != nil {
+		handleWriteErrors("Unable to unmarshal annotations body", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+
+	strict, err := h.isStrictWrite(r)
 	if err != nil {
-		handleWriteErrors("Unable to unmarshal annotations body", err, writeLog, w, http.StatusBadRequest)
+		handleWriteErrors("Invalid request", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
 		return
 	}
+	if strict {
+		if errs := validateAnnotations(draftAnnotations.Annotations, bodyIndexes(conversions)); len(errs) > 0 {
+			writeInvalidAnnotations(errs, writeLog, w)
+			return
+		}
+	}
 
-	savedAnnotations, newHash, err := h.saveAndReturnAnnotations(ctx, draftAnnotations.Annotations, writeLog, oldHash, contentUUID)
+	saved, err := h.saveAndReturnAnnotations(ctx, draftAnnotations.Annotations, writeLog, oldHash, contentUUID)
 	if err != nil {
-		handleWriteErrors("Error writing draft annotations", err, writeLog, w, http.StatusInternalServerError)
+		handleWriteErrors("Error writing draft annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set(annotations.DocumentHashHeader, newHash)
+	saved.writeHeaders(w)
 
-	err = json.NewEncoder(w).Encode(savedAnnotations)
+	var response interface{} = saved.annotations
+	if uppPayload {
+		reportAugmenterDrops(conversions, saved.dropped)
+		response = &uppWriteResponse{Annotations: saved.annotations.Annotations, Conversions: conversions}
+	}
+	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
-		handleWriteErrors("Error in encoding draft annotations response", err, writeLog, w, http.StatusInternalServerError)
+		handleWriteErrors("Error in encoding draft annotations response", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 }
 
 // ReplaceAnnotation deletes an annotation for a specific content uuid and adds a new one.
-// It gets the annotations only from UPP skipping V2 annotations because they are not editorially curated.
+// The annotations the new concept ends up with are merged following the predicate precedence, and the response describes the merges.
+// It gets only the editable annotations from UPP, as the others are not editorially curated.
 func (h *Handler) ReplaceAnnotation(w http.ResponseWriter, r *http.Request) {
 	w.Header().Add("Content-Type", "application/json")
 
@@ -238,7 +386,7 @@ func (h *Handler) ReplaceAnnotation(w http.ResponseWriter, r *http.Request) {
 	oldHash := r.Header.Get(annotations.PreviousDocumentHashHeader)
 
 	if err := validateUUID(conceptUUID); err != nil {
-		handleWriteErrors("invalid concept UUID", err, writeLog, w, http.StatusBadRequest)
+		handleWriteErrors("invalid concept UUID", CodeInvalidConceptID, err, writeLog, w, http.StatusBadRequest)
 		return
 	}
 
@@ -248,156 +396,257 @@ func (h *Handler) ReplaceAnnotation(w http.ResponseWriter, r *http.Request) {
 	dec := json.NewDecoder(r.Body)
 	err := dec.Decode(&addedAnnotation)
 	if err != nil {
-		handleWriteErrors("Error decoding request body", err, writeLog, w, http.StatusBadRequest)
+		handleWriteErrors("Error decoding request body", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
 		return
 	}
 	if addedAnnotation.Predicate != "" {
 		if !mapper.IsValidPACPredicate(addedAnnotation.Predicate) {
-			handleWriteErrors("Invalid request", errors.New("invalid predicate"), writeLog, w, http.StatusBadRequest)
+			handleWriteErrors("Invalid request", CodeInvalidPredicate, errors.New("invalid predicate"), writeLog, w, http.StatusBadRequest)
 			return
 		}
 	}
 	writeLog.Debug("Validating input and reading annotations from UPP...")
-	uppList, httpStatus, err := h.prepareUPPAnnotations(ctx, contentUUID, addedAnnotation.ConceptId)
+	uppList, publishedCached, err := h.prepareUPPAnnotations(ctx, contentUUID, addedAnnotation.ConceptId)
 	if err != nil {
-		handleWriteErrors("Error while preparing annotations", err, writeLog, w, httpStatus)
+		handleWriteErrors("Error while preparing annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
+	replaced := make(map[int]bool)
 	for i := range uppList {
 		if uppList[i].ConceptId == conceptUUID {
 			uppList[i].ConceptId = addedAnnotation.ConceptId
 			if addedAnnotation.Predicate != "" {
 				uppList[i].Predicate = addedAnnotation.Predicate
 			}
+			replaced[i] = true
+		}
+	}
+
+	merged := []MergedAnnotation{}
+	if len(replaced) > 0 {
+		uppList, merged = mergeAnnotations(uppList, replaced, addedAnnotation.ConceptId, h.predicatePrecedence)
+		if len(merged) > 0 {
+			writeLog.WithField("merged", merged).Info("Merged the annotations of the replacing concept")
 		}
 	}
 
-	_, newHash, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
+	saved, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
 	if err != nil {
-		handleWriteErrors("Error writing draft annotations", err, writeLog, w, http.StatusInternalServerError)
+		handleWriteErrors("Error writing draft annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
 		return
 	}
 
-	w.Header().Set(annotations.DocumentHashHeader, newHash)
+	saved.writeHeaders(w)
+	setPublishedCachedHeader(w, publishedCached)
+
+	err = json.NewEncoder(w).Encode(&replaceResponse{Annotations: saved.annotations.Annotations, Merged: merged})
+	if err != nil {
+		handleWriteErrors("Error in encoding draft annotations response", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
+	}
 }
 
-func (h *Handler) prepareUPPAnnotations(ctx context.Context, contentUUID string, conceptID string) ([]annotations.Annotation, int, error) {
+// prepareUPPAnnotations validates the IDs and gets the editable published annotations of the content,
+// telling whether they come from the published annotations cache.
+func (h *Handler) prepareUPPAnnotations(ctx context.Context, contentUUID string, conceptID string) ([]annotations.Annotation, bool, error) {
 
 	if err := validateUUID(contentUUID); err != nil {
-		return nil, http.StatusBadRequest, fmt.Errorf("invalid content ID : %w", err)
+		return nil, false, &requestError{code: CodeInvalidContentUUID, err: fmt.Errorf("invalid content ID : %w", err)}
 	}
 
-	if conceptID != mapper.TransformConceptID(conceptID) {
-		return nil, http.StatusBadRequest, errors.New("invalid concept ID URI")
+	if err := validateConceptID(conceptID); err != nil {
+		return nil, false, err
 	}
-	i := strings.LastIndex(conceptID, "/")
-	if i == -1 || i == len(conceptID)-1 {
-		return nil, http.StatusBadRequest, errors.New("concept ID is empty")
+
+	ann, cached, err := h.getEditablePublished(ctx, contentUUID)
+	if err != nil {
+		return nil, false, withUpstream(UpstreamUPPAnnotations, err)
 	}
-	if err := validateUUID(conceptID[i+1:]); err != nil {
-		return nil, http.StatusBadRequest, fmt.Errorf("invalid concept ID : %w", err)
+	return ann, cached, nil
+}
+
+// saveAndReturnAnnotations writes the augmented and canonicalized annotations as the draft of the content,
+// unless the stored draft already holds the same canonical annotations, in which case its hash is returned as it is.
+// The saved draft tells whether it has been written, and whether it has been augmented in degraded mode.
+func (h *Handler) saveAndReturnAnnotations(ctx context.Context, uppList []annotations.Annotation, writeLog *log.Entry, oldHash string, contentUUID string) (*savedDraft, error) {
+	saved, err := h.prepareAnnotationsForWrite(ctx, uppList, writeLog)
+	if err != nil {
+		return nil, err
 	}
 
-	ann, err := h.annotationsAPI.GetAllButV2(ctx, contentUUID)
+	current, currentHash, hasDraft, err := h.annotationsRW.Read(ctx, contentUUID)
 	if err != nil {
-		var uppErr annotations.UPPError
-		if errors.As(err, &uppErr) && uppErr.Status() == http.StatusNotFound {
-			return nil, uppErr.Status(), err
-		}
-		return nil, http.StatusInternalServerError, err
+		writeLog.WithError(err).Warn("Unable to read the current draft annotations, writing them anyway")
+	} else if hasDraft && (oldHash == "" || oldHash == currentHash) && h.c14n.Equal(current.Annotations, saved.annotations.Annotations) {
+		writeLog.Debug("Draft annotations are unchanged, skipping the write")
+		h.indexDraft(ctx, contentUUID, saved.annotations.Annotations, writeLog)
+		saved.hash = currentHash
+		return saved, nil
+	}
+
+	writeLog.Debug("Writing to annotations RW...")
+	newHash, err := h.annotationsRW.Write(ctx, contentUUID, saved.annotations, oldHash)
+	h.reads.invalidate(contentUUID)
+	if err != nil {
+		return nil, withUpstream(UpstreamAnnotationsRW, err)
 	}
-	return ann, http.StatusOK, nil
+	h.indexDraft(ctx, contentUUID, saved.annotations.Annotations, writeLog)
+	saved.hash = newHash
+	saved.written = true
+	return saved, nil
 }
 
-func (h *Handler) saveAndReturnAnnotations(ctx context.Context, uppList []annotations.Annotation, writeLog *log.Entry, oldHash string, contentUUID string) (*annotations.Annotations, string, error) {
+// prepareAnnotationsForWrite augments, switches to hasBrand and canonicalizes the annotations, for a draft yet to be saved.
+func (h *Handler) prepareAnnotationsForWrite(ctx context.Context, uppList []annotations.Annotation, writeLog *log.Entry) (*savedDraft, error) {
 	writeLog.Debug("Move to HasBrand annotations...")
-	uppList, err := h.annotationsAugmenter.AugmentAnnotations(ctx, uppList)
+	augmented, degraded, err := h.augment(ctx, uppList, h.degradedWrites, writeLog)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	uppList, err = switchToHasBrand(uppList)
+	dropped := droppedByAugmenter(uppList, augmented)
+	augmented, err = switchToHasBrand(augmented)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 	writeLog.Debug("Canonicalizing annotations...")
-	uppList = h.c14n.Canonicalize(uppList)
-	writeLog.Debug("Writing to annotations RW...")
-	newAnnotations := &annotations.Annotations{Annotations: uppList}
-	newHash, err := h.annotationsRW.Write(ctx, contentUUID, newAnnotations, oldHash)
-	if err != nil {
-		return nil, "", err
+	augmented = h.c14n.Canonicalize(augmented)
+	return &savedDraft{annotations: &annotations.Annotations{Annotations: augmented}, degraded: degraded, dropped: dropped}, nil
+}
+
+// droppedByAugmenter returns the given annotations missing from the augmented ones, whether their concept has been merged or not.
+func droppedByAugmenter(given []annotations.Annotation, augmented []annotations.Annotation) []annotations.Annotation {
+	kept := make(map[annotations.Annotation]struct{}, 2*len(augmented))
+	for _, ann := range augmented {
+		kept[annotations.Annotation{Predicate: ann.Predicate, ConceptId: extractConceptUUID(ann.ConceptId)}] = struct{}{}
+		if ann.MergedFrom != "" {
+			kept[annotations.Annotation{Predicate: ann.Predicate, ConceptId: extractConceptUUID(ann.MergedFrom)}] = struct{}{}
+		}
+	}
+	var dropped []annotations.Annotation
+	for _, ann := range given {
+		if _, found := kept[annotations.Annotation{Predicate: ann.Predicate, ConceptId: extractConceptUUID(ann.ConceptId)}]; !found {
+			dropped = append(dropped, ann)
+		}
 	}
-	return newAnnotations, newHash, nil
+	return dropped
 }
 
-func (h *Handler) readAnnotations(ctx context.Context, contentUUID string, showHasBrand bool, readLog *log.Entry) ([]annotations.Annotation, string, error) {
-	var (
-		result        []annotations.Annotation
-		hash          string
-		hasDraft      bool
-		err           error
-		rwAnnotations *annotations.Annotations
-	)
+// readAnnotations returns the augmented draft annotations of the content, or its published annotations if it has no draft,
+// with the hash of the draft, and whether they have been augmented in degraded mode or come from the published annotations cache.
+func (h *Handler) readAnnotations(ctx context.Context, contentUUID string, showHasBrand bool, readLog *log.Entry) (readResult, error) {
 	readLog.Info("Reading Annotations from Annotations R/W")
-	rwAnnotations, hash, hasDraft, err = h.annotationsRW.Read(ctx, contentUUID)
-
+	rwAnnotations, hash, hasDraft, err := h.annotationsRW.Read(ctx, contentUUID)
 	if err != nil {
-		return nil, hash, err
+		return readResult{}, withUpstream(UpstreamAnnotationsRW, err)
 	}
 
+	result := readResult{hash: hash}
 	if hasDraft {
-		result = rwAnnotations.Annotations
+		result.annotations = rwAnnotations.Annotations
 	} else {
 		readLog.Info("Annotations not found, retrieving annotations from UPP")
-		result, err = h.annotationsAPI.GetAll(ctx, contentUUID)
+		result.annotations, result.publishedCached, err = h.getPublished(ctx, contentUUID)
+		result.missingLifecycles, err = missingLifecycles(err, readLog)
 		if err != nil {
-			return nil, hash, err
+			return readResult{}, withUpstream(UpstreamUPPAnnotations, err)
 		}
 	}
 	readLog.Info("Augmenting annotations with recent UPP data")
-	result, err = h.annotationsAugmenter.AugmentAnnotations(ctx, result)
+	result.annotations, result.degraded, err = h.augment(ctx, result.annotations, true, readLog)
 	if err != nil {
 		readLog.WithError(err).Error("Failed to augment annotations")
-		return nil, hash, err
+		return readResult{}, err
+	}
+
+	if hasDraft && !result.degraded && h.migrateMergedConcepts && hasMergedConcepts(result.annotations) {
+		result.hash = h.migrateDraft(ctx, contentUUID, showHasBrand, rwAnnotations.Annotations, result.annotations, result.hash, readLog)
 	}
 
 	if !showHasBrand {
-		result = switchToIsClassifiedBy(result)
+		result.annotations = switchToIsClassifiedBy(result.annotations)
 	}
 
-	return result, hash, err
+	return result, nil
 }
 
-func handleReadErrors(err error, readLog *log.Entry, w http.ResponseWriter) {
-	if isTimeoutErr(err) {
-		readLog.WithError(err).Error("Timeout while reading annotations.")
-		writeMessage(w, "Timeout while reading annotations", http.StatusGatewayTimeout)
-		return
+// migrateDraft rewrites the stored draft, replacing the concept IDs the augmenter found to be merged with their canonical ones.
+// Every other stored annotation is written back as it is, including those the augmenter dropped.
+// It returns the hash of the rewritten draft, or the given hash when it could not be rewritten.
+func (h *Handler) migrateDraft(ctx context.Context, contentUUID string, showHasBrand bool, stored []annotations.Annotation, augmented []annotations.Annotation, hash string, readLog *log.Entry) string {
+	readLog.Info("Draft annotations refer to merged concepts, rewriting them with the canonical concept IDs")
+	migrated := &annotations.Annotations{Annotations: h.c14n.Canonicalize(rewriteMergedConcepts(stored, augmented))}
+	newHash, err := h.annotationsRW.Write(ctx, contentUUID, migrated, hash)
+	// The migration runs within the coalesced read it serves the result of, which must not be invalidated.
+	h.reads.invalidateOthers(contentUUID, showHasBrand)
+	if err != nil {
+		readLog.WithError(err).Warn("Failed to rewrite draft annotations with the canonical concept IDs")
+		return hash
 	}
-	var uppErr annotations.UPPError
-	if errors.As(err, &uppErr) {
-		if uppErr.UPPBody() != nil {
-			readLog.Info("UPP responded with a client error, forwarding UPP response back to client.")
-			w.WriteHeader(uppErr.Status())
-			w.Write(uppErr.UPPBody())
-			return
+	h.indexDraft(ctx, contentUUID, migrated.Annotations, readLog)
+	return newHash
+}
+
+// rewriteMergedConcepts replaces the concept IDs of the stored annotations the augmented ones mark as merged.
+// As merged concepts may collapse into the same canonical concept, the result is deduped on predicate and concept ID.
+func rewriteMergedConcepts(stored []annotations.Annotation, augmented []annotations.Annotation) []annotations.Annotation {
+	canonicalIDs := make(map[string]string)
+	for _, ann := range augmented {
+		if ann.MergedFrom != "" {
+			canonicalIDs[extractConceptUUID(ann.MergedFrom)] = ann.ConceptId
 		}
-		writeMessage(w, uppErr.Error(), uppErr.Status())
-		return
 	}
-	writeMessage(w, fmt.Sprintf("Failed to read annotations: %v", err), http.StatusInternalServerError)
+
+	type annotationKey struct{ predicate, conceptID string }
+	seen := make(map[annotationKey]struct{})
+	rewritten := make([]annotations.Annotation, 0, len(stored))
+	for _, ann := range stored {
+		if canonicalID, merged := canonicalIDs[extractConceptUUID(ann.ConceptId)]; merged {
+			ann.ConceptId = canonicalID
+		}
+		key := annotationKey{ann.Predicate, ann.ConceptId}
+		if _, duplicate := seen[key]; duplicate {
+			continue
+		}
+		seen[key] = struct{}{}
+		rewritten = append(rewritten, ann)
+	}
+	return rewritten
 }
 
-func handleWriteErrors(msg string, err error, writeLog *log.Entry, w http.ResponseWriter, httpStatus int) {
-	msg = fmt.Sprintf(msg+": %v", err.Error())
-	if isTimeoutErr(err) {
-		msg = "Timeout while waiting to write draft annotations"
-		httpStatus = http.StatusGatewayTimeout
+func hasMergedConcepts(augmented []annotations.Annotation) bool {
+	for _, ann := range augmented {
+		if ann.MergedFrom != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func handleReadErrors(err error, readLog *log.Entry, w http.ResponseWriter) {
+	p := newProblem(err, http.StatusInternalServerError, CodeInternalError, fmt.Sprintf("Failed to read annotations: %v", err))
+	p.TransactionID = transactionID(readLog)
+
+	var uppErr annotations.UPPError
+	switch {
+	case p.Code == CodeTimeout:
+		p.Detail = "Timeout while reading annotations"
+		readLog.WithError(err).Error("Timeout while reading annotations.")
+	case errors.As(err, &uppErr):
+		p.Detail = uppMessage(uppErr)
+		readLog.WithError(err).Info("UPP responded with an error")
+	}
+	problem.Write(w, p)
+}
+
+func handleWriteErrors(msg string, code string, err error, writeLog *log.Entry, w http.ResponseWriter, httpStatus int) {
+	p := newProblem(err, httpStatus, code, fmt.Sprintf(msg+": %v", err.Error()))
+	p.TransactionID = transactionID(writeLog)
+	if p.Code == CodeTimeout {
+		p.Detail = "Timeout while waiting to write draft annotations"
 	}
 
-	writeLog.WithError(err).Error(msg)
-	writeMessage(w, msg, httpStatus)
+	writeLog.WithError(err).Error(p.Detail)
+	problem.Write(w, p)
 }
 
 func readLogEntry(ctx context.Context, contentUUID string) *log.Entry {
@@ -413,28 +662,26 @@ func isTimeoutErr(err error) bool {
 	return e.Timeout()
 }
 
+// validateConceptID checks that the concept ID is a PAC concept URI ending with a valid UUID.
+func validateConceptID(conceptID string) error {
+	if conceptID != mapper.TransformConceptID(conceptID) {
+		return &requestError{code: CodeInvalidConceptID, err: errors.New("invalid concept ID URI")}
+	}
+	i := strings.LastIndex(conceptID, "/")
+	if i == -1 || i == len(conceptID)-1 {
+		return &requestError{code: CodeInvalidConceptID, err: errors.New("concept ID is empty")}
+	}
+	if err := validateUUID(conceptID[i+1:]); err != nil {
+		return &requestError{code: CodeInvalidConceptID, err: fmt.Errorf("invalid concept ID : %w", err)}
+	}
+	return nil
+}
+
 func validateUUID(u string) error {
 	_, err := uuid.FromString(u)
 	return err
 }
 
-func writeMessage(w http.ResponseWriter, msg string, status int) {
-	w.WriteHeader(status)
-
-	message := make(map[string]interface{})
-	message["message"] = msg
-	j, err := json.Marshal(&message)
-	if err != nil {
-		log.WithError(err).Error("Failed to parse provided message to json, this is a bug.")
-		return
-	}
-
-	_, err = w.Write(j)
-	if err != nil {
-		log.WithError(err).Error("Failed to parse response message.")
-	}
-}
-
 func switchToHasBrand(toChange []annotations.Annotation) ([]annotations.Annotation, error) {
 	changed := make([]annotations.Annotation, len(toChange))
 	for idx, ann := range toChange {
@@ -451,6 +698,15 @@ func switchToHasBrand(toChange []annotations.Annotation) ([]annotations.Annotati
 	return changed, nil
 }
 
+// hasPredicate tells whether the annotation has the given PAC predicate,
+// knowing that the UPP annotations of brands have the isClassifiedBy predicate instead of hasBrand.
+func hasPredicate(ann annotations.Annotation, predicate string) bool {
+	if predicate == mapper.PredicateHasBrand && ann.Predicate == mapper.PredicateIsClassifiedBy && ann.Type == mapper.ConceptTypeBrand {
+		return true
+	}
+	return ann.Predicate == predicate
+}
+
 func switchToIsClassifiedBy(toChange []annotations.Annotation) []annotations.Annotation {
 	changed := make([]annotations.Annotation, len(toChange))
 	for idx, ann := range toChange {
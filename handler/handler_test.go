@@ -17,6 +17,7 @@ import (
 
 	"github.com/Financial-Times/draft-annotations-api/annotations"
 	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
 	"github.com/Financial-Times/go-ft-http/fthttp"
 	tidutils "github.com/Financial-Times/transactionid-utils-go"
 	randomdata "github.com/Pallinder/go-randomdata"
@@ -148,6 +149,164 @@ func TestReadHasBrandAnnotation(t *testing.T) {
 	}
 }
 
+func TestReadAnnotationsMigratesMergedConcepts(t *testing.T) {
+	oldHash := randomdata.RandStringRunes(56)
+	newHash := randomdata.RandStringRunes(56)
+
+	draft := &annotations.Annotations{
+		Annotations: []annotations.Annotation{
+			{
+				Predicate: "http://www.ft.com/ontology/annotation/mentions",
+				ConceptId: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
+			},
+		},
+	}
+	augmented := []annotations.Annotation{
+		{
+			Predicate:  "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId:  "http://www.ft.com/thing/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+			ApiUrl:     "http://api.ft.com/people/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+			Type:       "http://www.ft.com/ontology/person/Person",
+			PrefLabel:  "Frederick Stapleton",
+			MergedFrom: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
+		},
+	}
+	migrated := &annotations.Annotations{
+		Annotations: []annotations.Annotation{
+			{
+				Predicate: "http://www.ft.com/ontology/annotation/mentions",
+				ConceptId: "http://www.ft.com/thing/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		opts         []handler.Option
+		writeErr     error
+		expectWrite  bool
+		expectedHash string
+	}{
+		"migration disabled": {
+			expectedHash: oldHash,
+		},
+		"migration enabled": {
+			opts:         []handler.Option{handler.WithMergedConceptsMigration()},
+			expectWrite:  true,
+			expectedHash: newHash,
+		},
+		"migration rejected by RW": {
+			opts:         []handler.Option{handler.WithMergedConceptsMigration()},
+			writeErr:     annotations.ErrUnexpectedStatusWrite,
+			expectWrite:  true,
+			expectedHash: oldHash,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rw := new(RWMock)
+			rw.On("Read", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(draft, oldHash, true, nil)
+			if test.expectWrite {
+				rw.On("Write", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895", migrated, oldHash).Return(newHash, test.writeErr)
+			}
+			aug := new(AugmenterMock)
+			aug.On("AugmentAnnotations", mock.Anything, draft.Annotations).Return(augmented, nil)
+			annAPI := new(AnnotationsAPIMock)
+
+			h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second, test.opts...)
+			r := vestigo.NewRouter()
+			r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+
+			req := httptest.NewRequest("GET", "http://api.ft.com/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", nil)
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			actual := annotations.Annotations{}
+			err := json.NewDecoder(resp.Body).Decode(&actual)
+			assert.NoError(t, err)
+
+			assert.Equal(t, augmented, actual.Annotations)
+			assert.Equal(t, test.expectedHash, resp.Header.Get(annotations.DocumentHashHeader))
+
+			rw.AssertExpectations(t)
+			aug.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReadAnnotationsMigrationKeepsUnknownConcepts(t *testing.T) {
+	oldHash := randomdata.RandStringRunes(56)
+	newHash := randomdata.RandStringRunes(56)
+
+	unknown := annotations.Annotation{
+		Predicate: "http://www.ft.com/ontology/annotation/about",
+		ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+	}
+	draft := &annotations.Annotations{
+		Annotations: []annotations.Annotation{
+			unknown,
+			{
+				Predicate: "http://www.ft.com/ontology/annotation/mentions",
+				ConceptId: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
+			},
+			{
+				Predicate: "http://www.ft.com/ontology/annotation/mentions",
+				ConceptId: "http://www.ft.com/thing/e1b4b2d1-2b56-4a9c-8a6f-7d0e1e3b8f0c",
+			},
+		},
+	}
+	augmented := []annotations.Annotation{
+		{
+			Predicate:  "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId:  "http://www.ft.com/thing/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+			PrefLabel:  "Frederick Stapleton",
+			MergedFrom: "http://www.ft.com/thing/7b7dafa0-d54e-4c1d-8e22-3d452792acd2",
+		},
+		{
+			Predicate:  "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId:  "http://www.ft.com/thing/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+			PrefLabel:  "Frederick Stapleton",
+			MergedFrom: "http://www.ft.com/thing/e1b4b2d1-2b56-4a9c-8a6f-7d0e1e3b8f0c",
+		},
+	}
+	migrated := &annotations.Annotations{
+		Annotations: []annotations.Annotation{
+			unknown,
+			{
+				Predicate: "http://www.ft.com/ontology/annotation/mentions",
+				ConceptId: "http://www.ft.com/thing/28f8d585-37ea-4879-ae1c-f6c0580a43b8",
+			},
+		},
+	}
+
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(draft, oldHash, true, nil)
+	rw.On("Write", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895", migrated, oldHash).Return(newHash, nil)
+	aug := new(AugmenterMock)
+	aug.On("AugmentAnnotations", mock.Anything, draft.Annotations).Return(augmented, nil)
+	annAPI := new(AnnotationsAPIMock)
+
+	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second, handler.WithMergedConceptsMigration())
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+
+	req := httptest.NewRequest("GET", "http://api.ft.com/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", nil)
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	resp := w.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, newHash, resp.Header.Get(annotations.DocumentHashHeader))
+
+	rw.AssertExpectations(t)
+	aug.AssertExpectations(t)
+}
+
 func TestAddAnnotation(t *testing.T) {
 	rw := &RWMock{}
 	annAPI := &AnnotationsAPIMock{}
@@ -215,12 +374,15 @@ func TestAddAnnotation(t *testing.T) {
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
 			calledGetAll := false
+			rw.read = func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+				return nil, "", false, nil
+			}
 			rw.write = func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
 				assert.Equal(t, &annotations.Annotations{Annotations: test.saved}, a)
 				assert.Equal(t, oldHash, hash)
 				return newHash, nil
 			}
-			annAPI.getAllButV2 = func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			annAPI.getEditable = func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
 				calledGetAll = true
 				return []annotations.Annotation{}, nil
 			}
@@ -326,6 +488,9 @@ func TestWriteHasBrandAnnotation(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			rw.read = func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+				return nil, "", false, nil
+			}
 			rw.write = func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
 				assert.Equal(t, &annotations.Annotations{Annotations: test.saved}, a)
 				assert.Equal(t, oldHash, hash)
@@ -447,13 +612,16 @@ func TestReplaceHasBrandAnnotation(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
+			rw.read = func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+				return nil, "", false, nil
+			}
 			rw.write = func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
 				assert.Equal(t, &annotations.Annotations{Annotations: test.toStore}, a)
 				assert.Equal(t, oldHash, hash)
 				return newHash, nil
 			}
 			getAllCalled := false
-			annAPI.getAllButV2 = func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			annAPI.getEditable = func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
 				getAllCalled = true
 				return test.fromUpp, nil
 			}
@@ -509,7 +677,7 @@ func TestUnHappyFetchFromAnnotationsRW(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 	assert.NoError(t, err)
-	assert.Equal(t, `{"message":"Failed to read annotations: computer says no"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Failed to read annotations: computer says no","code":"annotations_rw_failed","transactionId":"test_tid","upstream":"annotations-rw"}`, string(body))
 	assert.Empty(t, resp.Header.Get(annotations.DocumentHashHeader))
 
 	rw.AssertExpectations(t)
@@ -538,7 +706,7 @@ func TestUnHappyAugmenter(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 	assert.NoError(t, err)
-	assert.Equal(t, `{"message":"Failed to read annotations: computer says no"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Failed to read annotations: computer says no","code":"concept_lookup_failed","transactionId":"test_tid","upstream":"internal-concordances-api"}`, string(body))
 	assert.Empty(t, resp.Header.Get(annotations.DocumentHashHeader))
 
 	rw.AssertExpectations(t)
@@ -547,8 +715,14 @@ func TestUnHappyAugmenter(t *testing.T) {
 }
 
 func TestFetchFromAnnotationsAPIIfNotFoundInRW(t *testing.T) {
+	// UPP returns the same annotations for every lifecycle, so they all come from the first of them.
+	published := make([]annotations.Annotation, len(expectedAnnotations.Annotations))
+	for i, ann := range expectedAnnotations.Annotations {
+		ann.Lifecycle = "pac"
+		published[i] = ann
+	}
 	aug := new(AugmenterMock)
-	aug.On("AugmentAnnotations", mock.Anything, expectedAnnotations.Annotations).Return(expectedAnnotations.Annotations, nil)
+	aug.On("AugmentAnnotations", mock.Anything, published).Return(published, nil)
 
 	rw := new(RWMock)
 
@@ -576,7 +750,7 @@ func TestFetchFromAnnotationsAPIIfNotFoundInRW(t *testing.T) {
 	err := json.NewDecoder(resp.Body).Decode(&actual)
 	assert.NoError(t, err)
 
-	assert.Equal(t, expectedAnnotations, actual)
+	assert.Equal(t, annotations.Annotations{Annotations: published}, actual)
 	assert.Empty(t, resp.Header.Get(annotations.DocumentHashHeader))
 
 	rw.AssertExpectations(t)
@@ -606,7 +780,7 @@ func TestFetchFromAnnotationsAPI404(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	assert.NoError(t, err)
-	assert.Equal(t, "not found", string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"UPP responded with not found","code":"upp_not_found","transactionId":"test_tid","upstream":"upp-annotations-api"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -635,7 +809,7 @@ func TestFetchFromAnnotationsAPI404NoAnnoPostMapping(t *testing.T) {
 
 	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
 	assert.NoError(t, err)
-	assert.Equal(t, "{\"message\":\"No annotations found\"}", string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"No annotations found","code":"no_annotations","transactionId":"test_tid","upstream":"upp-annotations-api"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -663,7 +837,7 @@ func TestFetchFromAnnotationsAPI500(t *testing.T) {
 
 	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
 	assert.NoError(t, err)
-	assert.Equal(t, `{"message":"Service unavailable"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"Service unavailable","code":"upp_unavailable","transactionId":"test_tid","upstream":"upp-annotations-api"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1121,6 +1295,7 @@ func TestSaveAnnotations(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
 	rw := new(RWMock)
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsBody, oldHash).Return(newHash, nil)
 
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
@@ -1192,7 +1367,7 @@ func TestSaveAnnotationsInvalidContentUUID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	body, err := ioutil.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	assert.JSONEq(t, `{"message":"Invalid content UUID: uuid: UUID string too short: not-a-valid-uuid"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Invalid content UUID: uuid: UUID string too short: not-a-valid-uuid","code":"invalid_content_uuid","transactionId":"test_tid"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1223,7 +1398,7 @@ func TestSaveAnnotationsInvalidAnnotationsBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	body, err := ioutil.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	assert.JSONEq(t, `{"message":"Unable to unmarshal annotations body: invalid character 'i' looking for beginning of object key string"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Bad Request","status":400,"detail":"Unable to unmarshal annotations body: invalid character 'i' looking for beginning of object key string","code":"invalid_request","transactionId":"test_tid"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1233,6 +1408,7 @@ func TestSaveAnnotationsInvalidAnnotationsBody(t *testing.T) {
 func TestSaveAnnotationsErrorFromRW(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	rw := new(RWMock)
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsBody, oldHash).Return("", errors.New("computer says no"))
 
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
@@ -1269,7 +1445,7 @@ func TestSaveAnnotationsErrorFromRW(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
 	body, err := ioutil.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	assert.JSONEq(t, `{"message":"Error writing draft annotations: computer says no"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"Error writing draft annotations: computer says no","code":"annotations_rw_failed","transactionId":"test_tid","upstream":"annotations-rw"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1294,7 +1470,7 @@ func TestAnnotationsReadTimeoutGenericRW(t *testing.T) {
 	r.ServeHTTP(w, req)
 	resp := w.Result()
 	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
-	assert.JSONEq(t, `{"message":"Timeout while reading annotations"}`, w.Body.String())
+	assert.JSONEq(t, `{"type":"about:blank","title":"Gateway Timeout","status":504,"detail":"Timeout while reading annotations","code":"timeout","transactionId":"test_tid","upstream":"annotations-rw"}`, w.Body.String())
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1320,7 +1496,7 @@ func TestAnnotationsReadTimeoutUPP(t *testing.T) {
 	r.ServeHTTP(w, req)
 	resp := w.Result()
 	assert.Equal(t, http.StatusGatewayTimeout, resp.StatusCode)
-	assert.JSONEq(t, `{"message":"Timeout while reading annotations"}`, w.Body.String())
+	assert.JSONEq(t, `{"type":"about:blank","title":"Gateway Timeout","status":504,"detail":"Timeout while reading annotations","code":"timeout","transactionId":"test_tid","upstream":"upp-annotations-api"}`, w.Body.String())
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1349,6 +1525,7 @@ func TestIsTimeoutErr(t *testing.T) {
 func TestAnnotationsWriteTimeout(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	rw := new(RWMock)
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsBody, oldHash).Return("", &url.Error{Err: context.DeadlineExceeded})
 
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
@@ -1387,7 +1564,7 @@ func TestAnnotationsWriteTimeout(t *testing.T) {
 
 	body, err := ioutil.ReadAll(resp.Body)
 	assert.NoError(t, err)
-	assert.JSONEq(t, `{"message":"Timeout while waiting to write draft annotations"}`, string(body))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Gateway Timeout","status":504,"detail":"Timeout while waiting to write draft annotations","code":"timeout","transactionId":"test_tid","upstream":"annotations-rw"}`, string(body))
 
 	rw.AssertExpectations(t)
 	aug.AssertExpectations(t)
@@ -1398,10 +1575,11 @@ func TestHappyDeleteAnnotations(t *testing.T) {
 	rw := new(RWMock)
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895",
 		&expectedCanonicalisedAnnotationsAfterDelete, oldHash).Return(newHash, nil)
 	annAPI := new(AnnotationsAPIMock)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
 		Return(expectedAnnotations.Annotations, nil)
 
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
@@ -1437,6 +1615,108 @@ func TestHappyDeleteAnnotations(t *testing.T) {
 	annAPI.AssertExpectations(t)
 }
 
+func TestDeleteAnnotationWithPredicate(t *testing.T) {
+	contentUUID := "83a201c6-60cd-11e7-91a7-502f7ee26895"
+	companyID := "http://www.ft.com/thing/0d9fbdfc-7c84-4ea8-92d8-1a4e0e1b81a7"
+	brandID := "http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54"
+	published := []annotations.Annotation{
+		{Predicate: mapper.PredicateAbout, ConceptId: companyID, Type: "http://www.ft.com/ontology/company/PublicCompany"},
+		{Predicate: mapper.PredicateMentions, ConceptId: companyID, Type: "http://www.ft.com/ontology/company/PublicCompany"},
+		{Predicate: mapper.PredicateIsClassifiedBy, ConceptId: brandID, Type: mapper.ConceptTypeBrand},
+	}
+
+	tests := map[string]struct {
+		conceptID string
+		predicate string
+		expected  []annotations.Annotation
+	}{
+		"only the matching predicate": {
+			conceptID: companyID,
+			predicate: mapper.PredicateMentions,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: companyID},
+				{Predicate: mapper.PredicateHasBrand, ConceptId: brandID},
+			},
+		},
+		"hasBrand of a brand annotated with isClassifiedBy in UPP": {
+			conceptID: brandID,
+			predicate: mapper.PredicateHasBrand,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: companyID},
+				{Predicate: mapper.PredicateMentions, ConceptId: companyID},
+			},
+		},
+		"all the predicates": {
+			conceptID: companyID,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateHasBrand, ConceptId: brandID},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var written *annotations.Annotations
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					return nil, "", false, nil
+				},
+				write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+					written = a
+					return "new-hash", nil
+				},
+			}
+			annAPI := &AnnotationsAPIMock{
+				getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+					return append([]annotations.Annotation(nil), published...), nil
+				},
+			}
+			aug := &AugmenterMock{
+				augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+					return depletedAnnotations, nil
+				},
+			}
+
+			h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
+			r := vestigo.NewRouter()
+			r.Delete("/drafts/content/:uuid/annotations/:cuuid", h.DeleteAnnotation)
+
+			path := "/drafts/content/" + contentUUID + "/annotations/" + test.conceptID[strings.LastIndex(test.conceptID, "/")+1:]
+			if test.predicate != "" {
+				path += "?predicate=" + url.QueryEscape(test.predicate)
+			}
+			req := httptest.NewRequest("DELETE", path, nil)
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, &annotations.Annotations{Annotations: test.expected}, written)
+
+			var actual annotations.Annotations
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&actual))
+			assert.Equal(t, test.expected, actual.Annotations, "the response should contain the resulting draft annotations")
+		})
+	}
+}
+
+func TestDeleteAnnotationInvalidPredicate(t *testing.T) {
+	h := handler.New(&RWMock{}, &AnnotationsAPIMock{}, nil, &AugmenterMock{}, time.Second)
+	r := vestigo.NewRouter()
+	r.Delete("/drafts/content/:uuid/annotations/:cuuid", h.DeleteAnnotation)
+
+	req := httptest.NewRequest("DELETE", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations/0d9fbdfc-7c84-4ea8-92d8-1a4e0e1b81a7?predicate=http://www.ft.com/ontology/annotation/majorMentions", nil)
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var problem handler.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, handler.CodeInvalidPredicate, problem.Code)
+}
+
 func TestUnHappyDeleteAnnotationsMissingContentUUID(t *testing.T) {
 	rw := new(RWMock)
 	annAPI := new(AnnotationsAPIMock)
@@ -1484,7 +1764,7 @@ func TestUnHappyDeleteAnnotationsInvalidConceptUUID(t *testing.T) {
 func TestUnHappyDeleteAnnotationsWhenRetrievingAnnotationsFails(t *testing.T) {
 	rw := new(RWMock)
 	annAPI := new(AnnotationsAPIMock)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
 		Return([]annotations.Annotation{}, errors.New("sorry something failed"))
 	aug := new(AugmenterMock)
 
@@ -1511,7 +1791,7 @@ func TestUnHappyDeleteAnnotationsWhenNoAnnotationsFound(t *testing.T) {
 
 	uppErr := annotations.NewUPPError(annotations.UPPNotFoundMsg, http.StatusNotFound, nil)
 
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
 		Return([]annotations.Annotation{}, uppErr)
 	aug := new(AugmenterMock)
 
@@ -1535,9 +1815,10 @@ func TestUnHappyDeleteAnnotationsWhenNoAnnotationsFound(t *testing.T) {
 
 func TestUnHappyDeleteAnnotationsWhenWritingAnnotationsFails(t *testing.T) {
 	rw := new(RWMock)
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsBody, "").Return(mock.Anything, errors.New("sorry something failed"))
 	annAPI := new(AnnotationsAPIMock)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").
 		Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 
@@ -1573,8 +1854,9 @@ func TestHappyAddAnnotation(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterAdditon, oldHash).Return(newHash, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -1621,8 +1903,9 @@ func TestHappyAddExistingAnnotation(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsBody, oldHash).Return(newHash, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -1667,9 +1950,10 @@ func TestHappyAddAnnotationWithExistingConceptIdDifferentPredicate(t *testing.T)
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsSameConceptId, oldHash).Return(newHash, nil)
 	annAPI := new(AnnotationsAPIMock)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -1851,8 +2135,9 @@ func TestUnhappyAddAnnotationWhenWritingAnnotationsFails(t *testing.T) {
 	rw := new(RWMock)
 	annAPI := new(AnnotationsAPIMock)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterAdditon, "").Return(mock.Anything, errors.New("error writing annotations"))
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -1891,8 +2176,9 @@ func TestUnhappyAddAnnotationWhenGettingAnnotationsFails(t *testing.T) {
 	annAPI := new(AnnotationsAPIMock)
 	aug := new(AugmenterMock)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterAdditon, "").Return(mock.Anything, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, errors.New("error getting annotations"))
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, errors.New("error getting annotations"))
 
 	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
 	r := vestigo.NewRouter()
@@ -1925,8 +2211,9 @@ func TestUnhappyAddAnnotationWhenNoAnnotationsFound(t *testing.T) {
 
 	uppErr := annotations.NewUPPError(annotations.UPPNotFoundMsg, http.StatusNotFound, nil)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterAdditon, "").Return(mock.Anything, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, uppErr)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, uppErr)
 
 	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
 	r := vestigo.NewRouter()
@@ -1961,8 +2248,9 @@ func TestHappyReplaceAnnotation(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterReplace, oldHash).Return(newHash, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -2048,8 +2336,9 @@ func TestHappyReplaceAnnotationWithPredicate(t *testing.T) {
 		},
 	}
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), contentID).Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), contentID, &annotations.Annotations{Annotations: afterReplace}, oldHash).Return(newHash, nil)
-	annAPI.On("GetAllButV2", mock.Anything, contentID).Return(fromAnnotationAPI, nil)
+	annAPI.On("GetEditable", mock.Anything, contentID).Return(fromAnnotationAPI, nil)
 
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
@@ -2093,8 +2382,9 @@ func TestHappyReplaceExistingAnnotation(t *testing.T) {
 	oldHash := randomdata.RandStringRunes(56)
 	newHash := randomdata.RandStringRunes(56)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedAnnotationsReplaceExisting, oldHash).Return(newHash, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotationsReplace.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotationsReplace.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -2267,8 +2557,9 @@ func TestUnhappyReplaceAnnotationWhenWritingAnnotationsFails(t *testing.T) {
 	rw := new(RWMock)
 	annAPI := new(AnnotationsAPIMock)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterReplace, "").Return(mock.Anything, errors.New("error writing annotations"))
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, nil)
 	canonicalizer := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
 	aug := &AugmenterMock{
 		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
@@ -2305,8 +2596,9 @@ func TestUnhappyReplaceAnnotationWhenGettingAnnotationsFails(t *testing.T) {
 	annAPI := new(AnnotationsAPIMock)
 	aug := new(AugmenterMock)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterAdditon, "").Return(mock.Anything, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, errors.New("error getting annotations"))
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, errors.New("error getting annotations"))
 
 	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
 	r := vestigo.NewRouter()
@@ -2337,8 +2629,9 @@ func TestUnhappyReplaceAnnotationWhenNoAnnotationsFound(t *testing.T) {
 
 	uppErr := annotations.NewUPPError(annotations.UPPNotFoundMsg, http.StatusNotFound, nil)
 
+	rw.On("Read", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
 	rw.On("Write", mock.AnythingOfType("*context.valueCtx"), "83a201c6-60cd-11e7-91a7-502f7ee26895", &expectedCanonicalisedAnnotationsAfterAdditon, "").Return(mock.Anything, nil)
-	annAPI.On("GetAllButV2", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, uppErr)
+	annAPI.On("GetEditable", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(expectedAnnotations.Annotations, uppErr)
 
 	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
 	r := vestigo.NewRouter()
@@ -2377,6 +2670,114 @@ func (m *AugmenterMock) AugmentAnnotations(ctx context.Context, depletedAnnotati
 	return args.Get(0).([]annotations.Annotation), args.Error(1)
 }
 
+func TestWriteSkippedWhenDraftIsUnchanged(t *testing.T) {
+	contentUUID := "83a201c6-60cd-11e7-91a7-502f7ee26895"
+	stored := []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+		},
+	}
+	published := []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+			PrefLabel: "Barack H. Obama",
+		},
+	}
+
+	tests := map[string]struct {
+		method        string
+		path          string
+		body          string
+		oldHash       string
+		expectWritten bool
+		expectedHash  string
+	}{
+		"re-adding an existing annotation": {
+			method:       "POST",
+			path:         "/drafts/content/" + contentUUID + "/annotations",
+			body:         `{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}`,
+			oldHash:      "current-hash",
+			expectedHash: "current-hash",
+		},
+		"deleting an absent concept": {
+			method:       "DELETE",
+			path:         "/drafts/content/" + contentUUID + "/annotations/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed",
+			oldHash:      "current-hash",
+			expectedHash: "current-hash",
+		},
+		"without previous hash": {
+			method:       "DELETE",
+			path:         "/drafts/content/" + contentUUID + "/annotations/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed",
+			expectedHash: "current-hash",
+		},
+		"adding a new annotation": {
+			method:        "POST",
+			path:          "/drafts/content/" + contentUUID + "/annotations",
+			body:          `{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}`,
+			oldHash:       "current-hash",
+			expectWritten: true,
+			expectedHash:  "new-hash",
+		},
+		"stale previous hash": {
+			method:        "POST",
+			path:          "/drafts/content/" + contentUUID + "/annotations",
+			body:          `{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}`,
+			oldHash:       "stale-hash",
+			expectWritten: true,
+			expectedHash:  "new-hash",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			written := false
+			reads := 0
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					reads++
+					return &annotations.Annotations{Annotations: stored}, "current-hash", true, nil
+				},
+				write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+					written = true
+					assert.Equal(t, test.oldHash, hash)
+					return "new-hash", nil
+				},
+			}
+			annAPI := &AnnotationsAPIMock{
+				getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+					return published, nil
+				},
+			}
+			aug := &AugmenterMock{
+				augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+					return depletedAnnotations, nil
+				},
+			}
+
+			h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
+			r := vestigo.NewRouter()
+			r.Post("/drafts/content/:uuid/annotations", h.AddAnnotation)
+			r.Delete("/drafts/content/:uuid/annotations/:cuuid", h.DeleteAnnotation)
+
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			if test.oldHash != "" {
+				req.Header.Set(annotations.PreviousDocumentHashHeader, test.oldHash)
+			}
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, test.expectWritten, written)
+			assert.Equal(t, strconv.FormatBool(test.expectWritten), w.Header().Get(handler.DraftWrittenHeader))
+			assert.Equal(t, test.expectedHash, w.Header().Get(annotations.DocumentHashHeader))
+			assert.Equal(t, 1, reads, "the stored draft should always be compared before writing")
+		})
+	}
+}
+
 type RWMock struct {
 	mock.Mock
 	read     func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error)
@@ -2426,10 +2827,11 @@ func (m *RWMock) GTG() error {
 
 type AnnotationsAPIMock struct {
 	mock.Mock
-	getAll      func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error)
-	getAllButV2 func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error)
-	endpoint    func() string
-	gtg         func() error
+	getAll          func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error)
+	getEditable     func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error)
+	getByLifecycles func(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, error)
+	endpoint        func() string
+	gtg             func() error
 }
 
 func (m *AnnotationsAPIMock) GetAll(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
@@ -2440,14 +2842,22 @@ func (m *AnnotationsAPIMock) GetAll(ctx context.Context, contentUUID string) ([]
 	return args.Get(0).([]annotations.Annotation), args.Error(1)
 }
 
-func (m *AnnotationsAPIMock) GetAllButV2(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
-	if m.getAllButV2 != nil {
-		return m.getAllButV2(ctx, contentUUID)
+func (m *AnnotationsAPIMock) GetEditable(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+	if m.getEditable != nil {
+		return m.getEditable(ctx, contentUUID)
 	}
 	args := m.Called(ctx, contentUUID)
 	return args.Get(0).([]annotations.Annotation), args.Error(1)
 }
 
+func (m *AnnotationsAPIMock) GetByLifecycles(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, error) {
+	if m.getByLifecycles != nil {
+		return m.getByLifecycles(ctx, contentUUID, lifecycles)
+	}
+	args := m.Called(ctx, contentUUID, lifecycles)
+	return args.Get(0).([]annotations.Annotation), args.Error(1)
+}
+
 func (m *AnnotationsAPIMock) Endpoint() string {
 	if m.endpoint != nil {
 		return m.endpoint()
@@ -0,0 +1,252 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	log "github.com/sirupsen/logrus"
+)
+
+// DefaultMachineLifecycles are the lifecycles of the annotations suggested by the machine tagger.
+var DefaultMachineLifecycles = []string{"v2"}
+
+// WithMachineLifecycles sets the lifecycles of the machine annotations, shown apart from the editorial annotations
+// and promoted into the draft on demand. DefaultMachineLifecycles are used otherwise.
+func WithMachineLifecycles(lifecycles []string) Option {
+	return func(h *Handler) {
+		h.machineLifecycles = lifecycles
+	}
+}
+
+// sectionsResult is the outcome of readSections.
+type sectionsResult struct {
+	readResult
+	machine []annotations.Annotation
+}
+
+// readSections returns the augmented editorial annotations of the content, i.e. its draft or its editable
+// published annotations, and apart from them its machine annotations not already among the editorial ones.
+// Either section can be empty, but not both.
+func (h *Handler) readSections(ctx context.Context, contentUUID string, showHasBrand bool, readLog *log.Entry) (sectionsResult, error) {
+	readLog.Info("Reading Annotations from Annotations R/W")
+	rwAnnotations, hash, hasDraft, err := h.annotationsRW.Read(ctx, contentUUID)
+	if err != nil {
+		return sectionsResult{}, withUpstream(UpstreamAnnotationsRW, err)
+	}
+
+	result := sectionsResult{readResult: readResult{hash: hash}}
+	var notFound error
+	if hasDraft {
+		result.annotations = rwAnnotations.Annotations
+	} else {
+		readLog.Info("Annotations not found, retrieving editable annotations from UPP")
+		result.annotations, result.publishedCached, err = h.getEditablePublished(ctx, contentUUID)
+		result.missingLifecycles, err = missingLifecycles(err, readLog)
+		if isNotFound(err) {
+			notFound, err = err, nil
+		}
+		if err != nil {
+			return sectionsResult{}, withUpstream(UpstreamUPPAnnotations, err)
+		}
+	}
+
+	readLog.WithField("lifecycles", h.machineLifecycles).Info("Retrieving machine annotations from UPP")
+	machine, machineCached, missing, err := h.getPublishedByLifecycles(ctx, contentUUID, h.machineLifecycles, readLog)
+	if isNotFound(err) {
+		if notFound != nil {
+			return sectionsResult{}, withUpstream(UpstreamUPPAnnotations, notFound)
+		}
+		machine, err = nil, nil
+	}
+	if err != nil {
+		return sectionsResult{}, withUpstream(UpstreamUPPAnnotations, err)
+	}
+	result.publishedCached = result.publishedCached || machineCached
+	result.missingLifecycles = append(result.missingLifecycles, missing...)
+
+	readLog.Info("Augmenting annotations with recent UPP data")
+	var degraded bool
+	result.annotations, result.degraded, err = h.augment(ctx, result.annotations, true, readLog)
+	if err == nil {
+		machine, degraded, err = h.augment(ctx, machine, true, readLog)
+	}
+	if err != nil {
+		readLog.WithError(err).Error("Failed to augment annotations")
+		return sectionsResult{}, err
+	}
+	result.degraded = result.degraded || degraded
+
+	editorial := make(map[annotations.Annotation]struct{}, len(result.annotations))
+	for _, ann := range result.annotations {
+		editorial[annotationKey(ann)] = struct{}{}
+	}
+	for _, ann := range machine {
+		if _, found := editorial[annotationKey(ann)]; !found {
+			result.machine = append(result.machine, ann)
+		}
+	}
+
+	if !showHasBrand {
+		result.annotations = switchToIsClassifiedBy(result.annotations)
+		result.machine = switchToIsClassifiedBy(result.machine)
+	}
+	return result, nil
+}
+
+// readAnnotationSections serves a read asking for the editorial and machine annotations in separate sections.
+func (h *Handler) readAnnotationSections(ctx context.Context, w http.ResponseWriter, contentUUID string, showHasBrand bool, filter *annotationsFilter, readLog *log.Entry) {
+	result, err := h.readSections(ctx, contentUUID, showHasBrand, readLog)
+	if err != nil {
+		handleReadErrors(err, readLog, w)
+		return
+	}
+	if result.hash != "" {
+		w.Header().Set(annotations.DocumentHashHeader, result.hash)
+	}
+	if result.degraded {
+		w.Header().Set(DegradedHeader, "true")
+	}
+	setPublishedCachedHeader(w, result.publishedCached)
+	setMissingLifecyclesHeader(w, result.missingLifecycles)
+
+	if err := writeSections(w, result, filter); err != nil {
+		readLog.WithError(err).Error("Failed to encode response")
+		handleReadErrors(err, readLog, w)
+	}
+}
+
+// writeSections encodes the editorial and machine annotations in separate sections of the JSON response.
+func writeSections(w io.Writer, result sectionsResult, filter *annotationsFilter) error {
+	editorial, err := filter.project(filter.apply(result.annotations))
+	if err != nil {
+		return err
+	}
+	machine, err := filter.project(filter.apply(result.machine))
+	if err != nil {
+		return err
+	}
+	response := struct {
+		Annotations interface{} `json:"annotations"`
+		Machine     interface{} `json:"machine"`
+		Degraded    bool        `json:"degraded,omitempty"`
+	}{Annotations: editorial, Machine: machine, Degraded: result.degraded}
+	return json.NewEncoder(w).Encode(&response)
+}
+
+// PromoteAnnotations copies the selected machine annotations into the draft annotations of the content.
+// Like AddAnnotation, it starts from the editable annotations from UPP, as the others are not editorially curated.
+func (h *Handler) PromoteAnnotations(w http.ResponseWriter, r *http.Request) {
+	w.Header().Add("Content-Type", "application/json")
+
+	contentUUID := vestigo.Param(r, "uuid")
+
+	tID := tidutils.GetTransactionIDFromRequest(r)
+	ctx := tidutils.TransactionAwareContext(context.Background(), tID)
+	writeLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("uuid", contentUUID)
+
+	oldHash := r.Header.Get(annotations.PreviousDocumentHashHeader)
+
+	if err := validateUUID(contentUUID); err != nil {
+		handleWriteErrors("Invalid content UUID", CodeInvalidContentUUID, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+
+	selected := annotations.Annotations{}
+	if err := json.NewDecoder(r.Body).Decode(&selected); err != nil {
+		handleWriteErrors("Error decoding request body", CodeInvalidRequest, err, writeLog, w, http.StatusBadRequest)
+		return
+	}
+	if len(selected.Annotations) == 0 {
+		handleWriteErrors("Invalid request", CodeInvalidRequest, errors.New("no annotation to promote"), writeLog, w, http.StatusBadRequest)
+		return
+	}
+	for _, ann := range selected.Annotations {
+		if !mapper.IsValidPACPredicate(ann.Predicate) {
+			handleWriteErrors("Invalid request", CodeInvalidPredicate, errors.New("invalid predicate"), writeLog, w, http.StatusBadRequest)
+			return
+		}
+		if err := validateConceptID(ann.ConceptId); err != nil {
+			handleWriteErrors("Invalid request", CodeInvalidConceptID, err, writeLog, w, http.StatusBadRequest)
+			return
+		}
+	}
+
+	writeLog.Debug("Reading machine annotations from UPP...")
+	machine, _, _, err := h.getPublishedByLifecycles(ctx, contentUUID, h.machineLifecycles, writeLog)
+	if err != nil && !isNotFound(err) {
+		handleWriteErrors("Error while preparing annotations", CodeInternalError, withUpstream(UpstreamUPPAnnotations, err), writeLog, w, http.StatusInternalServerError)
+		return
+	}
+	// The selected annotations are the ones readSections returned, i.e. augmented and with isClassifiedBy brands.
+	machine, _, err = h.augment(ctx, machine, true, writeLog)
+	if err != nil {
+		handleWriteErrors("Error while preparing annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
+		return
+	}
+	available := make(map[annotations.Annotation]annotations.Annotation, len(machine))
+	for _, ann := range machine {
+		available[annotationKey(ann)] = annotationKey(ann)
+		available[annotationKey(switchToIsClassifiedBy([]annotations.Annotation{ann})[0])] = annotationKey(ann)
+	}
+	promoted := make([]annotations.Annotation, 0, len(selected.Annotations))
+	for _, ann := range selected.Annotations {
+		machineAnn, found := available[annotationKey(ann)]
+		if !found {
+			err := fmt.Errorf("not a machine annotation: %s %s", ann.Predicate, ann.ConceptId)
+			handleWriteErrors("Invalid request", CodeInvalidAnnotations, err, writeLog, w, http.StatusBadRequest)
+			return
+		}
+		promoted = append(promoted, machineAnn)
+	}
+
+	writeLog.Debug("Reading editable annotations from UPP...")
+	uppList, publishedCached, err := h.getEditablePublished(ctx, contentUUID)
+	if err != nil && !isNotFound(err) {
+		handleWriteErrors("Error while preparing annotations", CodeInternalError, withUpstream(UpstreamUPPAnnotations, err), writeLog, w, http.StatusInternalServerError)
+		return
+	}
+
+	present := make(map[annotations.Annotation]struct{}, len(uppList))
+	for _, ann := range uppList {
+		present[annotationKey(ann)] = struct{}{}
+	}
+	for _, ann := range promoted {
+		if _, found := present[ann]; found {
+			continue
+		}
+		present[ann] = struct{}{}
+		uppList = append(uppList, ann)
+	}
+
+	saved, err := h.saveAndReturnAnnotations(ctx, uppList, writeLog, oldHash, contentUUID)
+	if err != nil {
+		handleWriteErrors("Error writing draft annotations", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
+		return
+	}
+
+	saved.writeHeaders(w)
+	setPublishedCachedHeader(w, publishedCached)
+
+	err = json.NewEncoder(w).Encode(saved.annotations)
+	if err != nil {
+		handleWriteErrors("Error in encoding draft annotations response", CodeInternalError, err, writeLog, w, http.StatusInternalServerError)
+	}
+}
+
+// annotationKey identifies an annotation by its predicate and concept, whatever the form of the concept URI.
+func annotationKey(ann annotations.Annotation) annotations.Annotation {
+	return annotations.Annotation{Predicate: ann.Predicate, ConceptId: mapper.TransformConceptID(ann.ConceptId)}
+}
+
+func isNotFound(err error) bool {
+	var uppErr annotations.UPPError
+	return errors.As(err, &uppErr) && uppErr.Status() == http.StatusNotFound
+}
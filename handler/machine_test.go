@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	machineAbout    = `{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/d7de27f8-1633-3fcc-b308-c95a2ad7d1cd"}`
+	machineMentions = `{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a"}`
+)
+
+var (
+	editorialAnnotation = annotations.Annotation{
+		Predicate: "http://www.ft.com/ontology/annotation/about",
+		ConceptId: "http://www.ft.com/thing/d7de27f8-1633-3fcc-b308-c95a2ad7d1cd",
+	}
+	machineAnnotations = []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/d7de27f8-1633-3fcc-b308-c95a2ad7d1cd",
+			Lifecycle: "v2",
+		},
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			ConceptId: "http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a",
+			Lifecycle: "v2",
+		},
+	}
+)
+
+func newMachineRouter(h *handler.Handler) *vestigo.Router {
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+	r.Post("/drafts/content/:uuid/annotations/promote", h.PromoteAnnotations)
+	return r
+}
+
+func newMachineAnnotationsAPI(requested *[]string) *AnnotationsAPIMock {
+	return &AnnotationsAPIMock{
+		getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			return []annotations.Annotation{editorialAnnotation}, nil
+		},
+		getByLifecycles: func(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, error) {
+			*requested = lifecycles
+			return append([]annotations.Annotation(nil), machineAnnotations...), nil
+		},
+	}
+}
+
+func TestReadAnnotationsInSections(t *testing.T) {
+	var requested []string
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+	}
+	h := handler.New(rw, newMachineAnnotationsAPI(&requested), annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second,
+		handler.WithMachineLifecycles([]string{"v2", "v3"}))
+	r := newMachineRouter(h)
+
+	w := servePublished(r, "GET", "/drafts/content/"+publishedContentUUID+"/annotations?sections=true", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"v2", "v3"}, requested)
+	assert.JSONEq(t, `{"annotations":[`+machineAbout+`],"machine":[{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","lifecycle":"v2"}]}`, w.Body.String())
+
+	w = servePublished(r, "GET", "/drafts/content/"+publishedContentUUID+"/annotations?sections=true&format=upp", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestPromoteAnnotations(t *testing.T) {
+	var requested []string
+	var written *annotations.Annotations
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+			written = a
+			return "new-hash", nil
+		},
+	}
+	h := handler.New(rw, newMachineAnnotationsAPI(&requested), annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newMachineRouter(h)
+
+	w := servePublished(r, "POST", "/drafts/content/"+publishedContentUUID+"/annotations/promote", `{"annotations":[`+machineMentions+`]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, handler.DefaultMachineLifecycles, requested)
+	assert.Equal(t, "new-hash", w.Header().Get(annotations.DocumentHashHeader))
+
+	expected := `{"annotations":[` + machineAbout + `,` + machineMentions + `]}`
+	assert.JSONEq(t, expected, w.Body.String())
+	body, _ := json.Marshal(written)
+	assert.JSONEq(t, expected, string(body))
+}
+
+func TestPromoteAnnotationsRejectsOtherAnnotations(t *testing.T) {
+	var requested []string
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+			t.Error("the draft must not be written")
+			return "", nil
+		},
+	}
+	h := handler.New(rw, newMachineAnnotationsAPI(&requested), annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newMachineRouter(h)
+
+	tests := map[string]string{
+		"not a machine annotation": `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/hasAuthor","id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a"}]}`,
+		"invalid predicate":        `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/invalid","id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a"}]}`,
+		"invalid concept ID":       `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"not-a-concept"}]}`,
+		"no annotation":            `{"annotations":[]}`,
+	}
+	for name, body := range tests {
+		t.Run(name, func(t *testing.T) {
+			w := servePublished(r, "POST", "/drafts/content/"+publishedContentUUID+"/annotations/promote", body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+)
+
+// DefaultPredicatePrecedence is the order in which the predicates of a concept win over each other
+// when ReplaceAnnotation merges its annotations.
+var DefaultPredicatePrecedence = []string{mapper.PredicateAbout, mapper.PredicateMentions}
+
+// MergedAnnotation describes the annotations of a concept that ReplaceAnnotation has merged into the one with Predicate.
+// MergedPredicates has the predicate of every annotation that has been removed, including the duplicates of Predicate.
+type MergedAnnotation struct {
+	ConceptID        string   `json:"id"`
+	Predicate        string   `json:"predicate"`
+	MergedPredicates []string `json:"mergedPredicates"`
+}
+
+// replaceResponse is the ReplaceAnnotation response.
+type replaceResponse struct {
+	Annotations []annotations.Annotation `json:"annotations"`
+	Merged      []MergedAnnotation       `json:"merged"`
+}
+
+// WithPredicatePrecedence sets the order in which the predicates of a concept win over each other
+// when ReplaceAnnotation merges its annotations, from the strongest to the weakest.
+func WithPredicatePrecedence(predicates []string) Option {
+	return func(h *Handler) {
+		h.predicatePrecedence = predicates
+	}
+}
+
+// mergeAnnotations removes the duplicate annotations of the concept, and the annotations whose predicate is in the precedence
+// but is weaker than another predicate of the concept, when the replacement brought them together.
+// Replaced holds the indexes of the annotations the concept has been given by the replacement; the predicates the concept
+// already had together, or that the replaced concept had together, are left as they are.
+// The annotations of other concepts and with predicates not in the precedence are left as they are.
+func mergeAnnotations(list []annotations.Annotation, replaced map[int]bool, conceptID string, precedence []string) ([]annotations.Annotation, []MergedAnnotation) {
+	rank := make(map[string]int, len(precedence))
+	for i, p := range precedence {
+		rank[p] = i
+	}
+
+	existing := make(map[string]bool)
+	moved := make(map[string]bool)
+	for i, ann := range list {
+		if _, ranked := rank[ann.Predicate]; !ranked || ann.ConceptId != conceptID {
+			continue
+		}
+		if replaced[i] {
+			moved[ann.Predicate] = true
+		} else {
+			existing[ann.Predicate] = true
+		}
+	}
+
+	// mergedInto returns the predicate the given one is merged into, which is the strongest predicate
+	// it has been brought together with by the replacement, or itself.
+	mergedInto := func(predicate string) string {
+		for {
+			target := predicate
+			for _, p := range precedence {
+				if rank[p] >= rank[target] {
+					break
+				}
+				togetherBefore := (existing[p] && existing[predicate]) || (moved[p] && moved[predicate])
+				if (existing[p] || moved[p]) && !togetherBefore {
+					target = p
+					break
+				}
+			}
+			if target == predicate {
+				return predicate
+			}
+			predicate = target
+		}
+	}
+
+	merged := []MergedAnnotation{}
+	mergedIndex := make(map[string]int)
+	addMerged := func(kept string, removed string) {
+		i, found := mergedIndex[kept]
+		if !found {
+			i = len(merged)
+			mergedIndex[kept] = i
+			merged = append(merged, MergedAnnotation{ConceptID: conceptID, Predicate: kept})
+		}
+		merged[i].MergedPredicates = append(merged[i].MergedPredicates, removed)
+	}
+
+	kept := make(map[string]bool)
+	result := make([]annotations.Annotation, 0, len(list))
+	for _, ann := range list {
+		if ann.ConceptId != conceptID {
+			result = append(result, ann)
+			continue
+		}
+		predicate := ann.Predicate
+		if _, ranked := rank[predicate]; ranked {
+			predicate = mergedInto(predicate)
+		}
+		if kept[predicate] || predicate != ann.Predicate {
+			addMerged(predicate, ann.Predicate)
+			continue
+		}
+		kept[predicate] = true
+		result = append(result, ann)
+	}
+	return result, merged
+}
@@ -0,0 +1,162 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mergeContentUUID = "83a201c6-60cd-11e7-91a7-502f7ee26895"
+	mergeOldConcept  = "http://www.ft.com/thing/0d9fbdfc-7c84-4ea8-92d8-1a4e0e1b81a7"
+	mergeNewConcept  = "http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb"
+	mergeOtherBrand  = "http://www.ft.com/thing/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54"
+)
+
+func TestReplaceAnnotationMergesPredicates(t *testing.T) {
+	tests := map[string]struct {
+		opts           []handler.Option
+		published      []annotations.Annotation
+		body           string
+		expected       []annotations.Annotation
+		expectedMerged []handler.MergedAnnotation
+	}{
+		"new concept already annotated with a weaker predicate": {
+			published: []annotations.Annotation{
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeOldConcept},
+				{Predicate: mapper.PredicateHasAuthor, ConceptId: mergeOtherBrand},
+			},
+			body: `{"id":"` + mergeNewConcept + `"}`,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateHasAuthor, ConceptId: mergeOtherBrand},
+			},
+			expectedMerged: []handler.MergedAnnotation{
+				{ConceptID: mergeNewConcept, Predicate: mapper.PredicateAbout, MergedPredicates: []string{mapper.PredicateMentions}},
+			},
+		},
+		"old concept with several predicates replaced with a predicate": {
+			published: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeOldConcept},
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeOldConcept},
+			},
+			body: `{"id":"` + mergeNewConcept + `","predicate":"` + mapper.PredicateMentions + `"}`,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+			},
+			expectedMerged: []handler.MergedAnnotation{
+				{ConceptID: mergeNewConcept, Predicate: mapper.PredicateMentions, MergedPredicates: []string{mapper.PredicateMentions}},
+			},
+		},
+		"new concept already annotated with both predicates": {
+			published: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeOldConcept},
+			},
+			body: `{"id":"` + mergeNewConcept + `"}`,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+			},
+			expectedMerged: []handler.MergedAnnotation{
+				{ConceptID: mergeNewConcept, Predicate: mapper.PredicateMentions, MergedPredicates: []string{mapper.PredicateMentions}},
+			},
+		},
+		"old concept with both predicates": {
+			published: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeOldConcept},
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeOldConcept},
+			},
+			body: `{"id":"` + mergeNewConcept + `"}`,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+			},
+			expectedMerged: []handler.MergedAnnotation{},
+		},
+		"predicates outside the precedence are kept": {
+			published: []annotations.Annotation{
+				{Predicate: mapper.PredicateHasDisplayTag, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeOldConcept},
+			},
+			body: `{"id":"` + mergeNewConcept + `"}`,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateHasDisplayTag, ConceptId: mergeNewConcept},
+			},
+			expectedMerged: []handler.MergedAnnotation{},
+		},
+		"configured precedence": {
+			opts: []handler.Option{handler.WithPredicatePrecedence([]string{mapper.PredicateMentions, mapper.PredicateAbout})},
+			published: []annotations.Annotation{
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+				{Predicate: mapper.PredicateAbout, ConceptId: mergeOldConcept},
+			},
+			body: `{"id":"` + mergeNewConcept + `"}`,
+			expected: []annotations.Annotation{
+				{Predicate: mapper.PredicateMentions, ConceptId: mergeNewConcept},
+			},
+			expectedMerged: []handler.MergedAnnotation{
+				{ConceptID: mergeNewConcept, Predicate: mapper.PredicateMentions, MergedPredicates: []string{mapper.PredicateAbout}},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var written *annotations.Annotations
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					return nil, "", false, nil
+				},
+				write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+					written = a
+					return "new-hash", nil
+				},
+			}
+			annAPI := &AnnotationsAPIMock{
+				getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+					return test.published, nil
+				},
+			}
+			aug := &AugmenterMock{
+				augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+					return depletedAnnotations, nil
+				},
+			}
+
+			h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second, test.opts...)
+			r := vestigo.NewRouter()
+			r.Patch("/drafts/content/:uuid/annotations/:cuuid", h.ReplaceAnnotation)
+
+			req := httptest.NewRequest("PATCH", "/drafts/content/"+mergeContentUUID+"/annotations/0d9fbdfc-7c84-4ea8-92d8-1a4e0e1b81a7", strings.NewReader(test.body))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, &annotations.Annotations{Annotations: test.expected}, written)
+
+			var response struct {
+				Annotations []annotations.Annotation   `json:"annotations"`
+				Merged      []handler.MergedAnnotation `json:"merged"`
+			}
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+			assert.Equal(t, test.expected, response.Annotations)
+			assert.Equal(t, test.expectedMerged, response.Merged)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Machine-readable codes of the error responses.
+const (
+	CodeInvalidContentUUID       = "invalid_content_uuid"
+	CodeInvalidConceptID         = "invalid_concept_id"
+	CodeInvalidPredicate         = "invalid_predicate"
+	CodeInvalidAnnotations       = "invalid_annotations"
+	CodeInvalidRequest           = problem.CodeInvalidRequest
+	CodeNotAcceptable            = "not_acceptable"
+	CodeUPPNotFound              = "upp_not_found"
+	CodeUPPBadRequest            = "upp_bad_request"
+	CodeUPPUnavailable           = "upp_unavailable"
+	CodeNoAnnotations            = "no_annotations"
+	CodeConceptLookupFailed      = "concept_lookup_failed"
+	CodeHashConflict             = "hash_conflict"
+	CodeAnnotationsRWFailed      = "annotations_rw_failed"
+	CodeSuggestionsUnavailable   = "suggestions_unavailable"
+	CodeConceptSearchUnavailable = "concept_search_unavailable"
+	CodeConceptIndexUnavailable  = "concept_index_unavailable"
+	CodeTimeout                  = "timeout"
+	CodeInternalError            = "internal_error"
+)
+
+// Upstream services whose failures are reported in the error responses.
+const (
+	UpstreamAnnotationsRW  = "annotations-rw"
+	UpstreamUPPAnnotations = "upp-annotations-api"
+	UpstreamConcepts       = "internal-concordances-api"
+	UpstreamSuggestions    = "suggestions-api"
+	UpstreamConceptSearch  = "concept-search-api"
+	UpstreamConceptIndex   = "concept-index"
+)
+
+// Problem is the RFC 7807 body of the error responses, listing the invalid annotations of the rejected writes.
+type Problem struct {
+	problem.Problem
+
+	Errors []AnnotationError `json:"errors,omitempty"`
+}
+
+// upstreamError records the service a failed call was made to.
+type upstreamError struct {
+	upstream string
+	err      error
+}
+
+func (e *upstreamError) Error() string {
+	return e.err.Error()
+}
+
+func (e *upstreamError) Unwrap() error {
+	return e.err
+}
+
+func withUpstream(upstream string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &upstreamError{upstream: upstream, err: err}
+}
+
+// requestError is an error caused by an invalid request.
+type requestError struct {
+	code string
+	err  error
+}
+
+func (e *requestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *requestError) Unwrap() error {
+	return e.err
+}
+
+// newProblem describes err as a problem. The given status and code are used unless err is a timeout,
+// an invalid request or comes from an upstream service, which have codes of their own.
+func newProblem(err error, status int, code string, detail string) *Problem {
+	p := &Problem{Problem: *problem.New(status, code, detail)}
+
+	var upErr *upstreamError
+	if errors.As(err, &upErr) {
+		p.Upstream = upErr.upstream
+		switch upErr.upstream {
+		case UpstreamConcepts:
+			p.Status = http.StatusInternalServerError
+			p.Code = CodeConceptLookupFailed
+		case UpstreamAnnotationsRW:
+			p.Status = http.StatusInternalServerError
+			p.Code = CodeAnnotationsRWFailed
+		case UpstreamSuggestions:
+			p.Status = http.StatusServiceUnavailable
+			p.Code = CodeSuggestionsUnavailable
+		case UpstreamConceptSearch:
+			p.Status = http.StatusServiceUnavailable
+			p.Code = CodeConceptSearchUnavailable
+		case UpstreamConceptIndex:
+			p.Status = http.StatusServiceUnavailable
+			p.Code = CodeConceptIndexUnavailable
+		}
+	}
+
+	var uppErr annotations.UPPError
+	var reqErr *requestError
+	switch {
+	case isTimeoutErr(err) || errors.Is(err, context.DeadlineExceeded):
+		p.Status = http.StatusGatewayTimeout
+		p.Code = CodeTimeout
+	case errors.Is(err, annotations.ErrDocumentHashConflict):
+		p.Status = http.StatusConflict
+		p.Code = CodeHashConflict
+		if p.Upstream == "" {
+			p.Upstream = UpstreamAnnotationsRW
+		}
+	case errors.As(err, &uppErr):
+		p.Status = uppErr.Status()
+		p.Code = uppErrorCode(uppErr)
+		p.Upstream = UpstreamUPPAnnotations
+	case errors.As(err, &reqErr):
+		p.Status = http.StatusBadRequest
+		p.Code = reqErr.code
+	}
+
+	return p
+}
+
+func uppErrorCode(uppErr annotations.UPPError) string {
+	switch {
+	case uppErr.Error() == annotations.NoAnnotationsMsg:
+		return CodeNoAnnotations
+	case uppErr.Status() == http.StatusNotFound:
+		return CodeUPPNotFound
+	case uppErr.Status() == http.StatusBadRequest:
+		return CodeUPPBadRequest
+	default:
+		return CodeUPPUnavailable
+	}
+}
+
+// uppMessage returns the error message including the message of the UPP response body, if any.
+func uppMessage(uppErr annotations.UPPError) string {
+	if len(uppErr.UPPBody()) == 0 {
+		return uppErr.Error()
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(uppErr.UPPBody(), &body); err != nil || body.Message == "" {
+		return uppErr.Error()
+	}
+	return uppErr.Error() + ": " + body.Message
+}
+
+// transactionID returns the transaction ID the log entry has been created with.
+func transactionID(entry *log.Entry) string {
+	tID, _ := entry.Data[tidutils.TransactionIDKey].(string)
+	return tID
+}
@@ -0,0 +1,204 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/Financial-Times/draft-annotations-api/idempotency"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestErrorResponsesAreProblems(t *testing.T) {
+	const contentPath = "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations"
+	published := []annotations.Annotation{
+		{
+			Predicate: "http://www.ft.com/ontology/annotation/about",
+			ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+		},
+	}
+
+	tests := map[string]struct {
+		method           string
+		path             string
+		body             string
+		getEditableErr   error
+		writeErr         error
+		expectedStatus   int
+		expectedCode     string
+		expectedUpstream string
+	}{
+		"invalid content UUID": {
+			method:         "DELETE",
+			path:           "/drafts/content/not-a-uuid/annotations/0a619d71-9af5-3755-90dd-f789b686c67a",
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidContentUUID,
+		},
+		"invalid concept ID": {
+			method:         "PATCH",
+			path:           contentPath + "/not-a-uuid",
+			body:           `{"id":"http://www.ft.com/thing/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidConceptID,
+		},
+		"invalid predicate": {
+			method:         "POST",
+			path:           contentPath,
+			body:           `{"id":"http://www.ft.com/thing/100e3cc0-aecc-4458-8ebd-6b1fbc7345ed","predicate":"http://www.ft.com/ontology/unknown"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedCode:   handler.CodeInvalidPredicate,
+		},
+		"content not found in UPP": {
+			method:           "DELETE",
+			path:             contentPath + "/0a619d71-9af5-3755-90dd-f789b686c67a",
+			getEditableErr:   annotations.NewUPPError(annotations.UPPNotFoundMsg, http.StatusNotFound, nil),
+			expectedStatus:   http.StatusNotFound,
+			expectedCode:     handler.CodeUPPNotFound,
+			expectedUpstream: handler.UpstreamUPPAnnotations,
+		},
+		"hash conflict": {
+			method:           "DELETE",
+			path:             contentPath + "/0a619d71-9af5-3755-90dd-f789b686c67a",
+			writeErr:         fmt.Errorf("status %d: %w", http.StatusConflict, annotations.ErrDocumentHashConflict),
+			expectedStatus:   http.StatusConflict,
+			expectedCode:     handler.CodeHashConflict,
+			expectedUpstream: handler.UpstreamAnnotationsRW,
+		},
+		"RW failure": {
+			method:           "DELETE",
+			path:             contentPath + "/0a619d71-9af5-3755-90dd-f789b686c67a",
+			writeErr:         fmt.Errorf("status %d: %w", http.StatusServiceUnavailable, annotations.ErrUnexpectedStatusWrite),
+			expectedStatus:   http.StatusInternalServerError,
+			expectedCode:     handler.CodeAnnotationsRWFailed,
+			expectedUpstream: handler.UpstreamAnnotationsRW,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					return nil, "", false, nil
+				},
+				write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+					return "new-hash", test.writeErr
+				},
+			}
+			annAPI := &AnnotationsAPIMock{
+				getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+					return published, test.getEditableErr
+				},
+			}
+			aug := &AugmenterMock{
+				augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+					return depletedAnnotations, nil
+				},
+			}
+			h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
+			r := vestigo.NewRouter()
+			r.Post("/drafts/content/:uuid/annotations", h.AddAnnotation)
+			r.Delete("/drafts/content/:uuid/annotations/:cuuid", h.DeleteAnnotation)
+			r.Patch("/drafts/content/:uuid/annotations/:cuuid", h.ReplaceAnnotation)
+
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+			assert.Equal(t, problem.ContentType, resp.Header.Get("Content-Type"))
+
+			var p handler.Problem
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+			assert.Equal(t, test.expectedStatus, p.Status)
+			assert.Equal(t, http.StatusText(test.expectedStatus), p.Title)
+			assert.Equal(t, test.expectedCode, p.Code)
+			assert.Equal(t, test.expectedUpstream, p.Upstream)
+			assert.Equal(t, testTID, p.TransactionID)
+			assert.NotEmpty(t, p.Detail)
+		})
+	}
+}
+
+func TestReadErrorIncludesUPPMessage(t *testing.T) {
+	rw := new(RWMock)
+	rw.On("Read", mock.Anything, "83a201c6-60cd-11e7-91a7-502f7ee26895").Return(nil, "", false, nil)
+
+	annotationsAPIServerMock := newAnnotationsAPIServerMock(t, http.StatusBadRequest, `{"message":"Invalid lifecycle"}`)
+	defer annotationsAPIServerMock.Close()
+
+	annotationsAPI := annotations.NewUPPAnnotationsAPI(testClient, annotationsAPIServerMock.URL+"/content/%v/annotations", testAPIKey)
+	h := handler.New(rw, annotationsAPI, nil, new(AugmenterMock), time.Second)
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+
+	req := httptest.NewRequest("GET", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", nil)
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"detail": "UPP responded with a client error: Invalid lifecycle",
+		"code": "upp_bad_request",
+		"transactionId": "test_tid",
+		"upstream": "upp-annotations-api"
+	}`, w.Body.String())
+	rw.AssertExpectations(t)
+}
+
+func TestProblemCodesAreInTheSpec(t *testing.T) {
+	raw, err := ioutil.ReadFile("../_ft/api.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec struct {
+		Definitions struct {
+			Problem struct {
+				Properties struct {
+					Code     struct{ Enum []string } `yaml:"code"`
+					Upstream struct{ Enum []string } `yaml:"upstream"`
+				} `yaml:"properties"`
+			} `yaml:"Problem"`
+		} `yaml:"definitions"`
+	}
+	if err := yaml.Unmarshal(raw, &spec); err != nil {
+		t.Fatal(err)
+	}
+
+	codes := []string{
+		handler.CodeInvalidContentUUID, handler.CodeInvalidConceptID, handler.CodeInvalidPredicate, handler.CodeInvalidAnnotations,
+		handler.CodeInvalidRequest, handler.CodeNotAcceptable, handler.CodeUPPNotFound, handler.CodeUPPBadRequest,
+		handler.CodeUPPUnavailable, handler.CodeNoAnnotations, handler.CodeConceptLookupFailed, handler.CodeHashConflict,
+		handler.CodeAnnotationsRWFailed, handler.CodeSuggestionsUnavailable, handler.CodeConceptSearchUnavailable,
+		handler.CodeConceptIndexUnavailable, handler.CodeTimeout, handler.CodeInternalError,
+		idempotency.CodeKeyReused, idempotency.CodeKeyInFlight,
+	}
+	assert.ElementsMatch(t, codes, spec.Definitions.Problem.Properties.Code.Enum)
+
+	upstreams := []string{
+		handler.UpstreamAnnotationsRW, handler.UpstreamUPPAnnotations, handler.UpstreamConcepts,
+		handler.UpstreamSuggestions, handler.UpstreamConceptSearch, handler.UpstreamConceptIndex,
+	}
+	assert.ElementsMatch(t, upstreams, spec.Definitions.Problem.Properties.Upstream.Enum)
+}
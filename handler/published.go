@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	log "github.com/sirupsen/logrus"
+)
+
+// PublishedCachedHeader tells that the published annotations a response is based on come from the cache.
+const PublishedCachedHeader = "Published-Annotations-Cached"
+
+// MissingLifecyclesHeader lists the lifecycles whose published annotations are missing from a response,
+// as UPP failed to return them.
+const MissingLifecyclesHeader = "Annotations-Missing-Lifecycles"
+
+// CachedAnnotationsAPI is an AnnotationsAPI caching the published annotations, which tells when they come from its cache.
+type CachedAnnotationsAPI interface {
+	AnnotationsAPI
+	GetAllWithCacheStatus(ctx context.Context, contentUUID string) ([]annotations.Annotation, bool, error)
+	GetEditableWithCacheStatus(ctx context.Context, contentUUID string) ([]annotations.Annotation, bool, error)
+	GetByLifecyclesWithCacheStatus(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, bool, error)
+	Invalidate(contentUUID string)
+}
+
+// getPublished gets all the published annotations of the content, and whether they come from the cache.
+func (h *Handler) getPublished(ctx context.Context, contentUUID string) ([]annotations.Annotation, bool, error) {
+	if cached, ok := h.annotationsAPI.(CachedAnnotationsAPI); ok {
+		return cached.GetAllWithCacheStatus(ctx, contentUUID)
+	}
+	list, err := h.annotationsAPI.GetAll(ctx, contentUUID)
+	return list, false, err
+}
+
+// getEditablePublished gets the editable published annotations of the content, and whether they come from the cache.
+func (h *Handler) getEditablePublished(ctx context.Context, contentUUID string) ([]annotations.Annotation, bool, error) {
+	if cached, ok := h.annotationsAPI.(CachedAnnotationsAPI); ok {
+		return cached.GetEditableWithCacheStatus(ctx, contentUUID)
+	}
+	list, err := h.annotationsAPI.GetEditable(ctx, contentUUID)
+	return list, false, err
+}
+
+// getPublishedByLifecycles gets the published annotations of the given lifecycles, whether they come from the cache,
+// and the lifecycles whose annotations are missing as UPP failed to return them.
+func (h *Handler) getPublishedByLifecycles(ctx context.Context, contentUUID string, lifecycles []string, logEntry *log.Entry) ([]annotations.Annotation, bool, []string, error) {
+	var list []annotations.Annotation
+	var cached bool
+	var err error
+	if cachedAPI, ok := h.annotationsAPI.(CachedAnnotationsAPI); ok {
+		list, cached, err = cachedAPI.GetByLifecyclesWithCacheStatus(ctx, contentUUID, lifecycles)
+	} else {
+		list, err = h.annotationsAPI.GetByLifecycles(ctx, contentUUID, lifecycles)
+	}
+	missing, err := missingLifecycles(err, logEntry)
+	return list, cached, missing, err
+}
+
+// missingLifecycles returns the lifecycles UPP failed to return when err only tells that some of them are missing,
+// in which case the published annotations of the others can be read on their own. Otherwise err is returned as it is.
+// The writes never start from such partial annotations.
+func missingLifecycles(err error, logEntry *log.Entry) ([]string, error) {
+	var partialErr *annotations.PartialLifecyclesError
+	if errors.As(err, &partialErr) {
+		logEntry.WithError(err).Warn("Some lifecycles are missing from the published annotations")
+		return partialErr.Lifecycles, nil
+	}
+	return nil, err
+}
+
+// readPublishedAnnotations returns the augmented published annotations of the given lifecycles, ignoring any draft.
+// These reads are not coalesced, as they are not the usual reads of the editorial tools.
+func (h *Handler) readPublishedAnnotations(ctx context.Context, contentUUID string, lifecycles []string, showHasBrand bool, readLog *log.Entry) (readResult, error) {
+	readLog.WithField("lifecycles", lifecycles).Info("Retrieving annotations from UPP")
+	list, cached, missing, err := h.getPublishedByLifecycles(ctx, contentUUID, lifecycles, readLog)
+	if err != nil {
+		return readResult{}, withUpstream(UpstreamUPPAnnotations, err)
+	}
+
+	result := readResult{publishedCached: cached, missingLifecycles: missing}
+	result.annotations, result.degraded, err = h.augment(ctx, list, true, readLog)
+	if err != nil {
+		readLog.WithError(err).Error("Failed to augment annotations")
+		return readResult{}, err
+	}
+	if !showHasBrand {
+		result.annotations = switchToIsClassifiedBy(result.annotations)
+	}
+	return result, nil
+}
+
+// InvalidatePublishedAnnotations forgets the cached published annotations of a content, e.g. when it is republished,
+// together with the cached reads of the content.
+func (h *Handler) InvalidatePublishedAnnotations(w http.ResponseWriter, r *http.Request) {
+	contentUUID := vestigo.Param(r, "uuid")
+	tID := tidutils.GetTransactionIDFromRequest(r)
+	invalidateLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("uuid", contentUUID)
+
+	if err := validateUUID(contentUUID); err != nil {
+		handleWriteErrors("Invalid content UUID", CodeInvalidContentUUID, err, invalidateLog, w, http.StatusBadRequest)
+		return
+	}
+
+	if cached, ok := h.annotationsAPI.(CachedAnnotationsAPI); ok {
+		cached.Invalidate(contentUUID)
+	}
+	h.reads.invalidate(contentUUID)
+
+	invalidateLog.Info("Cached published annotations invalidated")
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func setPublishedCachedHeader(w http.ResponseWriter, cached bool) {
+	if cached {
+		w.Header().Set(PublishedCachedHeader, "true")
+	}
+}
+
+func setMissingLifecyclesHeader(w http.ResponseWriter, missing []string) {
+	if len(missing) > 0 {
+		w.Header().Set(MissingLifecyclesHeader, strings.Join(missing, ","))
+	}
+}
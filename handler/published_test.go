@@ -0,0 +1,203 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const publishedContentUUID = "83a201c6-60cd-11e7-91a7-502f7ee26895"
+
+var publishedAnnotations = []annotations.Annotation{
+	{
+		Predicate: "http://www.ft.com/ontology/annotation/mentions",
+		ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+	},
+}
+
+func newPublishedRouter(h *handler.Handler) *vestigo.Router {
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations", h.ReadAnnotations)
+	r.Post("/drafts/content/:uuid/annotations", h.AddAnnotation)
+	r.Delete("/__admin/published-annotations/:uuid", h.InvalidatePublishedAnnotations)
+	return r
+}
+
+func servePublished(r http.Handler, method string, path string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestReadAnnotationsFromPublishedCache(t *testing.T) {
+	getAllCalls := 0
+	annAPI := &AnnotationsAPIMock{
+		getAll: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			getAllCalls++
+			return publishedAnnotations, nil
+		},
+	}
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+	}
+	h := handler.New(rw, annotations.NewCachedPublishedAPI(annAPI, time.Minute), annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+	path := "/drafts/content/" + publishedContentUUID + "/annotations"
+
+	w := servePublished(r, "GET", path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(handler.PublishedCachedHeader))
+
+	w = servePublished(r, "GET", path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(handler.PublishedCachedHeader))
+	assert.JSONEq(t, `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"}]}`, w.Body.String())
+	assert.Equal(t, 1, getAllCalls)
+
+	w = servePublished(r, "DELETE", "/__admin/published-annotations/"+publishedContentUUID, "")
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = servePublished(r, "GET", path, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(handler.PublishedCachedHeader))
+	assert.Equal(t, 2, getAllCalls)
+}
+
+func TestAddAnnotationFromPublishedCache(t *testing.T) {
+	annAPI := &AnnotationsAPIMock{
+		getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			return publishedAnnotations, nil
+		},
+	}
+	var written []*annotations.Annotations
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+			written = append(written, a)
+			return "hash", nil
+		},
+	}
+	h := handler.New(rw, annotations.NewCachedPublishedAPI(annAPI, time.Minute), annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+	path := "/drafts/content/" + publishedContentUUID + "/annotations"
+	body := `{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb"}`
+
+	w := servePublished(r, "POST", path, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(handler.PublishedCachedHeader))
+
+	w = servePublished(r, "POST", path, body)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "true", w.Header().Get(handler.PublishedCachedHeader))
+
+	assert.Len(t, written, 2)
+	assert.Equal(t, written[0], written[1], "the cached published annotations are not modified by the writes")
+	assert.Len(t, written[1].Annotations, 2)
+}
+
+func TestInvalidatePublishedAnnotationsInvalidUUID(t *testing.T) {
+	h := handler.New(&RWMock{}, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+
+	w := servePublished(r, "DELETE", "/__admin/published-annotations/not-a-uuid", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), handler.CodeInvalidContentUUID)
+}
+
+func TestReadAnnotationsByLifecycles(t *testing.T) {
+	var requested []string
+	annAPI := &AnnotationsAPIMock{
+		getByLifecycles: func(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, error) {
+			requested = lifecycles
+			published := publishedAnnotations[0]
+			published.Lifecycle = "v2"
+			return []annotations.Annotation{published}, nil
+		},
+	}
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			t.Error("the draft must not be read")
+			return nil, "", false, nil
+		},
+	}
+	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+
+	w := servePublished(r, "GET", "/drafts/content/"+publishedContentUUID+"/annotations?lifecycle=v2&lifecycle=pac&fields=id,lifecycle", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []string{"v2", "pac"}, requested)
+	assert.Empty(t, w.Header().Get(annotations.DocumentHashHeader))
+	assert.JSONEq(t, `{"annotations":[{"id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a","lifecycle":"v2"}]}`, w.Body.String())
+}
+
+func TestReadAnnotationsByLifecyclesWithFailedLifecycle(t *testing.T) {
+	annAPI := &AnnotationsAPIMock{
+		getByLifecycles: func(ctx context.Context, contentUUID string, lifecycles []string) ([]annotations.Annotation, error) {
+			published := publishedAnnotations[0]
+			published.Lifecycle = "v2"
+			uppErr := annotations.NewUPPError(annotations.UPPServiceUnavailableMsg, http.StatusServiceUnavailable, nil)
+			return []annotations.Annotation{published}, &annotations.PartialLifecyclesError{Lifecycles: []string{"pac"}, Err: uppErr}
+		},
+	}
+	h := handler.New(&RWMock{}, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+
+	w := servePublished(r, "GET", "/drafts/content/"+publishedContentUUID+"/annotations?lifecycle=v2&lifecycle=pac&fields=id,lifecycle", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "pac", w.Header().Get(handler.MissingLifecyclesHeader))
+	assert.Empty(t, w.Header().Get(handler.DegradedHeader), "the concept data is not degraded")
+	assert.JSONEq(t, `{"annotations":[{"id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a","lifecycle":"v2"}]}`, w.Body.String())
+}
+
+func TestReadAnnotationsWithFailedLifecycle(t *testing.T) {
+	annAPI := &AnnotationsAPIMock{
+		getAll: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			published := publishedAnnotations[0]
+			published.Lifecycle = "pac"
+			uppErr := annotations.NewUPPError(annotations.UPPServiceUnavailableMsg, http.StatusServiceUnavailable, nil)
+			return []annotations.Annotation{published}, &annotations.PartialLifecyclesError{Lifecycles: []string{"v2", "next-video"}, Err: uppErr}
+		},
+	}
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+	}
+	h := handler.New(rw, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+
+	w := servePublished(r, "GET", "/drafts/content/"+publishedContentUUID+"/annotations?fields=id,lifecycle", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "v2,next-video", w.Header().Get(handler.MissingLifecyclesHeader))
+	assert.JSONEq(t, `{"annotations":[{"id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a","lifecycle":"pac"}]}`, w.Body.String())
+}
+
+func TestWriteFailsWithFailedEditableLifecycle(t *testing.T) {
+	annAPI := &AnnotationsAPIMock{
+		getEditable: func(ctx context.Context, contentUUID string) ([]annotations.Annotation, error) {
+			uppErr := annotations.NewUPPError(annotations.UPPServiceUnavailableMsg, http.StatusServiceUnavailable, nil)
+			return publishedAnnotations[:1], &annotations.PartialLifecyclesError{Lifecycles: []string{"v1"}, Err: uppErr}
+		},
+	}
+	h := handler.New(&RWMock{}, annAPI, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := newPublishedRouter(h)
+
+	body := `{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/6b43a14b-a5e0-3b63-a428-aa55def05fcb"}`
+	w := servePublished(r, "POST", "/drafts/content/"+publishedContentUUID+"/annotations", body)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code, "the draft must not start from partial published annotations")
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	log "github.com/sirupsen/logrus"
+)
+
+// AnnotationError describes why the annotation at Index of a request body is invalid.
+// Field and Message have the same meaning as in the request validation errors.
+type AnnotationError struct {
+	Index   int    `json:"index"`
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// isStrictWrite tells whether the annotations of the request must all be valid to be written,
+// which is the case when the handler has been created WithStrictWrites or the request has strict=true.
+func (h *Handler) isStrictWrite(r *http.Request) (bool, error) {
+	v := r.URL.Query().Get("strict")
+	if v == "" {
+		return h.strictWrites, nil
+	}
+	strict, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param strict: %s", v)}
+	}
+	return h.strictWrites || strict, nil
+}
+
+// validateAnnotations checks the predicate and concept ID of every annotation as AddAnnotation does.
+// indexes gives the position of each annotation in the request body, when it differs from its position in the list.
+func validateAnnotations(list []annotations.Annotation, indexes []int) []AnnotationError {
+	var errs []AnnotationError
+	for i, ann := range list {
+		index := i
+		if indexes != nil {
+			index = indexes[i]
+		}
+		if !mapper.IsValidPACPredicate(ann.Predicate) {
+			errs = append(errs, AnnotationError{
+				Index:   index,
+				Field:   fmt.Sprintf("body.annotations[%d].predicate", index),
+				Code:    CodeInvalidPredicate,
+				Message: fmt.Sprintf("invalid predicate: %q", ann.Predicate),
+			})
+		}
+		if err := validateConceptID(ann.ConceptId); err != nil {
+			errs = append(errs, AnnotationError{
+				Index:   index,
+				Field:   fmt.Sprintf("body.annotations[%d].id", index),
+				Code:    CodeInvalidConceptID,
+				Message: err.Error(),
+			})
+		}
+	}
+	return errs
+}
+
+// bodyIndexes returns the position in the request body of each converted UPP annotation.
+func bodyIndexes(conversions []mapper.Conversion) []int {
+	if conversions == nil {
+		return nil
+	}
+	indexes := make([]int, 0, len(conversions))
+	for _, c := range conversions {
+		if c.Action == mapper.ConversionConverted {
+			indexes = append(indexes, c.Index)
+		}
+	}
+	return indexes
+}
+
+func writeInvalidAnnotations(errs []AnnotationError, writeLog *log.Entry, w http.ResponseWriter) {
+	p := newProblem(nil, http.StatusBadRequest, CodeInvalidAnnotations, fmt.Sprintf("%d invalid annotation field(s)", len(errs)))
+	p.TransactionID = transactionID(writeLog)
+	p.Errors = errs
+
+	writeLog.WithField("errors", errs).Error("Invalid annotations")
+	problem.Write(w, p)
+}
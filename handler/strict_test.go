@@ -0,0 +1,160 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const invalidAnnotationsBody = `{
+	"annotations": [
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/mentions",
+			"id": "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/majorMentions",
+			"id": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://api.ft.com/things/1a2a1a0a-7199-38b8-8a73-e651e2172471"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/invalidPredicate",
+			"id": "http://www.ft.com/thing/not-a-uuid"
+		}
+	]
+}`
+
+func newStrictRouter(written *bool, opts ...handler.Option) *vestigo.Router {
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+			*written = true
+			return "newHash", nil
+		},
+	}
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			return depletedAnnotations, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second, opts...)
+	r := vestigo.NewRouter()
+	r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+	return r
+}
+
+func TestSaveAnnotationsStrict(t *testing.T) {
+	tests := map[string]struct {
+		opts  []handler.Option
+		query string
+	}{
+		"strict param":       {query: "?strict=true"},
+		"strict writes flag": {opts: []handler.Option{handler.WithStrictWrites()}},
+		"flag wins":          {opts: []handler.Option{handler.WithStrictWrites()}, query: "?strict=false"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			written := false
+			r := newStrictRouter(&written, test.opts...)
+
+			req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations"+test.query, strings.NewReader(invalidAnnotationsBody))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.False(t, written, "invalid annotations should not be written")
+
+			var problem handler.Problem
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+			assert.Equal(t, handler.CodeInvalidAnnotations, problem.Code)
+			assert.Equal(t, testTID, problem.TransactionID)
+			assert.Equal(t, []handler.AnnotationError{
+				{Index: 1, Field: "body.annotations[1].predicate", Code: handler.CodeInvalidPredicate, Message: `invalid predicate: "http://www.ft.com/ontology/annotation/majorMentions"`},
+				{Index: 2, Field: "body.annotations[2].id", Code: handler.CodeInvalidConceptID, Message: "invalid concept ID URI"},
+				{Index: 3, Field: "body.annotations[3].predicate", Code: handler.CodeInvalidPredicate, Message: `invalid predicate: "http://www.ft.com/ontology/invalidPredicate"`},
+				{Index: 3, Field: "body.annotations[3].id", Code: handler.CodeInvalidConceptID, Message: "invalid concept ID : uuid: UUID string too short: not-a-uuid"},
+			}, problem.Errors)
+		})
+	}
+}
+
+func TestSaveAnnotationsNotStrict(t *testing.T) {
+	written := false
+	r := newStrictRouter(&written)
+
+	req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", strings.NewReader(invalidAnnotationsBody))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, written)
+}
+
+func TestSaveAnnotationsStrictInvalidParam(t *testing.T) {
+	written := false
+	r := newStrictRouter(&written)
+
+	req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations?strict=maybe", strings.NewReader(invalidAnnotationsBody))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, written)
+
+	var problem handler.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, handler.CodeInvalidRequest, problem.Code)
+}
+
+func TestSaveUPPAnnotationsStrictReportsBodyIndexes(t *testing.T) {
+	written := false
+	r := newStrictRouter(&written)
+
+	body := `{
+		"annotations": [
+			{
+				"predicate": "http://www.ft.com/ontology/implicitlyAbout",
+				"id": "http://api.ft.com/things/82645c31-4426-4ef5-99c9-9df6e0940c00",
+				"types": ["http://www.ft.com/ontology/Topic"]
+			},
+			{
+				"predicate": "http://www.ft.com/ontology/annotation/mentions",
+				"id": "http://api.ft.com/things/not-a-uuid",
+				"types": ["http://www.ft.com/ontology/person/Person"]
+			}
+		]
+	}`
+	req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations?format=upp&strict=true", strings.NewReader(body))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, written)
+
+	var problem handler.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	if assert.Len(t, problem.Errors, 1) {
+		assert.Equal(t, 1, problem.Errors[0].Index)
+		assert.Equal(t, "body.annotations[1].id", problem.Errors[0].Field)
+	}
+}
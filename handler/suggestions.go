@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	"github.com/Financial-Times/draft-annotations-api/suggestion"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	log "github.com/sirupsen/logrus"
+)
+
+// Suggester gets the concepts suggested to annotate a content with.
+type Suggester interface {
+	GetSuggestions(ctx context.Context, contentUUID string) ([]suggestion.Suggestion, error)
+}
+
+// WithSuggester serves the annotation suggestions from s. Without it, the suggestions are unavailable.
+func WithSuggester(s Suggester) Option {
+	return func(h *Handler) {
+		h.suggester = s
+	}
+}
+
+// Suggestion is an annotation suggested for a content, augmented with the concept data, with its score.
+type Suggestion struct {
+	annotations.Annotation
+	Score float64 `json:"score"`
+}
+
+// GetSuggestions returns the annotations suggested for a content, from the highest score to the lowest.
+// The suggestions of concepts the content is already annotated with, of unknown concepts and with predicates
+// that are not valid in PAC are left out.
+func (h *Handler) GetSuggestions(w http.ResponseWriter, r *http.Request) {
+	contentUUID := vestigo.Param(r, "uuid")
+	tID := tidutils.GetTransactionIDFromRequest(r)
+
+	ctx, cancel := context.WithTimeout(tidutils.TransactionAwareContext(r.Context(), tID), h.timeout)
+	defer cancel()
+
+	readLog := readLogEntry(ctx, contentUUID)
+
+	if err := validateUUID(contentUUID); err != nil {
+		p := newProblem(err, http.StatusBadRequest, CodeInvalidContentUUID, "Invalid content UUID: "+err.Error())
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+	if h.suggester == nil {
+		p := newProblem(errors.New("no suggestion service configured"), http.StatusServiceUnavailable, CodeSuggestionsUnavailable, "No suggestion service configured")
+		p.TransactionID = tID
+		problem.Write(w, p)
+		return
+	}
+
+	suggestions, degraded, err := h.suggest(ctx, contentUUID, readLog)
+	if err != nil {
+		handleReadErrors(err, readLog, w)
+		return
+	}
+
+	w.Header().Set("Content-Type", ContentTypeJSON)
+	if degraded {
+		w.Header().Set(DegradedHeader, "true")
+	}
+	response := struct {
+		Suggestions []Suggestion `json:"suggestions"`
+		Degraded    bool         `json:"degraded,omitempty"`
+	}{Suggestions: suggestions, Degraded: degraded}
+	if err := json.NewEncoder(w).Encode(&response); err != nil {
+		readLog.WithError(err).Error("Failed to encode response")
+	}
+}
+
+// suggest gets the suggestions of the content which are not among its draft annotations, or its editable
+// published annotations if it has no draft, and augments them with the concept data.
+func (h *Handler) suggest(ctx context.Context, contentUUID string, readLog *log.Entry) ([]Suggestion, bool, error) {
+	readLog.Info("Getting annotation suggestions")
+	suggested, err := h.suggester.GetSuggestions(ctx, contentUUID)
+	if err != nil {
+		return nil, false, withUpstream(UpstreamSuggestions, err)
+	}
+
+	annotated, err := h.annotatedConcepts(ctx, contentUUID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	scores := make(map[annotations.Annotation]float64)
+	var candidates []annotations.Annotation
+	for _, s := range suggested {
+		ann := annotations.Annotation{Predicate: s.Predicate, ConceptId: mapper.TransformConceptID(s.ConceptId)}
+		if !mapper.IsValidPACPredicate(ann.Predicate) || validateConceptID(ann.ConceptId) != nil {
+			readLog.WithField("conceptId", s.ConceptId).WithField("predicate", s.Predicate).Debug("Invalid suggestion left out")
+			continue
+		}
+		if _, found := annotated[ann.ConceptId]; found {
+			continue
+		}
+		if score, found := scores[ann]; !found {
+			candidates = append(candidates, ann)
+			scores[ann] = s.Score
+		} else if s.Score > score {
+			scores[ann] = s.Score
+		}
+	}
+	if len(candidates) == 0 {
+		return []Suggestion{}, false, nil
+	}
+
+	augmented, degraded, err := h.augment(ctx, candidates, true, readLog)
+	if err != nil {
+		return nil, false, err
+	}
+
+	result := make([]Suggestion, 0, len(augmented))
+	for _, ann := range augmented {
+		if _, found := annotated[mapper.TransformConceptID(ann.ConceptId)]; found {
+			continue
+		}
+		suggestedID := ann.ConceptId
+		if ann.MergedFrom != "" {
+			suggestedID = ann.MergedFrom
+		}
+		score := scores[annotations.Annotation{Predicate: ann.Predicate, ConceptId: mapper.TransformConceptID(suggestedID)}]
+		if ann.Predicate == mapper.PredicateHasBrand {
+			ann.Predicate = mapper.PredicateIsClassifiedBy
+		}
+		ann.ConceptExpansion = nil
+		result = append(result, Suggestion{Annotation: ann, Score: score})
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	return result, degraded, nil
+}
+
+// annotatedConcepts returns the IDs of the concepts the draft annotations of the content refer to,
+// or its editable published annotations if it has no draft.
+func (h *Handler) annotatedConcepts(ctx context.Context, contentUUID string) (map[string]struct{}, error) {
+	draft, _, hasDraft, err := h.annotationsRW.Read(ctx, contentUUID)
+	if err != nil {
+		return nil, withUpstream(UpstreamAnnotationsRW, err)
+	}
+
+	var list []annotations.Annotation
+	if hasDraft {
+		list = draft.Annotations
+	} else {
+		list, _, err = h.getEditablePublished(ctx, contentUUID)
+		if err != nil && !isNotFound(err) {
+			return nil, withUpstream(UpstreamUPPAnnotations, err)
+		}
+	}
+
+	annotated := make(map[string]struct{}, len(list))
+	for _, ann := range list {
+		annotated[mapper.TransformConceptID(ann.ConceptId)] = struct{}{}
+	}
+	return annotated, nil
+}
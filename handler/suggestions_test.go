@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	"github.com/Financial-Times/draft-annotations-api/suggestion"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+type suggesterStub struct {
+	suggestions []suggestion.Suggestion
+	err         error
+}
+
+func (s *suggesterStub) GetSuggestions(ctx context.Context, contentUUID string) ([]suggestion.Suggestion, error) {
+	return s.suggestions, s.err
+}
+
+func newSuggestionsRouter(h *handler.Handler) *vestigo.Router {
+	r := vestigo.NewRouter()
+	r.Get("/drafts/content/:uuid/annotations/suggestions", h.GetSuggestions)
+	r.Patch("/drafts/content/:uuid/annotations/:cuuid", h.ReplaceAnnotation)
+	return r
+}
+
+func TestGetSuggestions(t *testing.T) {
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return &annotations.Annotations{Annotations: []annotations.Annotation{editorialAnnotation}}, "hash", true, nil
+		},
+	}
+	suggester := &suggesterStub{suggestions: []suggestion.Suggestion{
+		{ConceptId: "http://api.ft.com/things/d7de27f8-1633-3fcc-b308-c95a2ad7d1cd", Predicate: "http://www.ft.com/ontology/annotation/mentions", Score: 0.99},
+		{ConceptId: "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a", Predicate: "http://www.ft.com/ontology/annotation/mentions", Score: 0.4},
+		{ConceptId: "http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a", Predicate: "http://www.ft.com/ontology/annotation/about", Score: 0.8},
+		{ConceptId: "http://www.ft.com/thing/838b3fbe-efbc-3cfe-b5c0-d38c046492a4", Predicate: "http://www.ft.com/ontology/annotation/invalid", Score: 0.9},
+		{ConceptId: "http://www.ft.com/thing/9577c6d4-b09e-4552-b88f-e52745abe02b", Predicate: "http://www.ft.com/ontology/annotation/about", Score: 0.7},
+	}}
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, list []annotations.Annotation) ([]annotations.Annotation, error) {
+			var augmented []annotations.Annotation
+			for _, ann := range list {
+				// the concept of 9577c6d4 is unknown
+				if ann.ConceptId == "http://www.ft.com/thing/9577c6d4-b09e-4552-b88f-e52745abe02b" {
+					continue
+				}
+				ann.PrefLabel = "label of " + ann.ConceptId[len(ann.ConceptId)-4:]
+				augmented = append(augmented, ann)
+			}
+			return augmented, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second, handler.WithSuggester(suggester))
+
+	w := servePublished(newSuggestionsRouter(h), "GET", "/drafts/content/"+publishedContentUUID+"/annotations/suggestions", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"suggestions":[
+		{"predicate":"http://www.ft.com/ontology/annotation/about","id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","prefLabel":"label of 0e6a","score":0.8},
+		{"predicate":"http://www.ft.com/ontology/annotation/mentions","id":"http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a","prefLabel":"label of c67a","score":0.4}
+	]}`, w.Body.String())
+}
+
+func TestGetSuggestionsUnavailable(t *testing.T) {
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+	}
+	c14n := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
+
+	h := handler.New(rw, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second)
+	w := servePublished(newSuggestionsRouter(h), "GET", "/drafts/content/"+publishedContentUUID+"/annotations/suggestions", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"suggestions_unavailable"`)
+
+	h = handler.New(rw, &AnnotationsAPIMock{}, c14n, identityAugmenter, time.Second, handler.WithSuggester(&suggesterStub{err: errors.New("computer says no")}))
+	w = servePublished(newSuggestionsRouter(h), "GET", "/drafts/content/"+publishedContentUUID+"/annotations/suggestions", "")
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"upstream":"suggestions-api"`)
+
+	w = servePublished(newSuggestionsRouter(h), "GET", "/drafts/content/not-a-uuid/annotations/suggestions", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
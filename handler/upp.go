@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+)
+
+// ContentTypeUPPAnnotations is the media type of a WriteAnnotations body holding UPP format annotations.
+const ContentTypeUPPAnnotations = "application/vnd.ft-upp-annotations+json"
+
+// uppWriteResponse is the WriteAnnotations response to a UPP format body,
+// reporting how each of the UPP annotations has been converted to PAC or why it has been dropped.
+type uppWriteResponse struct {
+	Annotations []annotations.Annotation `json:"annotations"`
+	Conversions []mapper.Conversion      `json:"conversions"`
+}
+
+// isUPPPayload tells whether the WriteAnnotations body holds UPP format annotations,
+// which is signalled by either the format query param or the Content-Type header.
+func isUPPPayload(r *http.Request) (bool, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case formatUPP:
+		return true, nil
+	case "", formatPAC:
+	default:
+		return false, &requestError{code: CodeInvalidRequest, err: fmt.Errorf("invalid param format: %s", format)}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == ContentTypeUPPAnnotations, nil
+}
+
+// decodeUPPAnnotations decodes a body of UPP format annotations and converts them to PAC,
+// with the same mapping as the annotations read from UPP.
+// The annotations are either wrapped in an object, as PAC annotations are, or a bare array, as UPP returns them.
+func decodeUPPAnnotations(r io.Reader) ([]annotations.Annotation, []mapper.Conversion, error) {
+	var uppAnnotations json.RawMessage
+	if err := json.NewDecoder(r).Decode(&uppAnnotations); err != nil {
+		return nil, nil, err
+	}
+	if len(uppAnnotations) > 0 && uppAnnotations[0] != '[' {
+		var body struct {
+			Annotations json.RawMessage `json:"annotations"`
+		}
+		if err := json.Unmarshal(uppAnnotations, &body); err != nil {
+			return nil, nil, err
+		}
+		uppAnnotations = body.Annotations
+	}
+	if len(uppAnnotations) == 0 || string(uppAnnotations) == "null" {
+		return nil, []mapper.Conversion{}, nil
+	}
+
+	converted, conversions, err := mapper.ConvertPredicatesWithReport(uppAnnotations)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var list []annotations.Annotation
+	if converted != nil {
+		if err := json.Unmarshal(converted, &list); err != nil {
+			return nil, nil, err
+		}
+	}
+	return list, conversions, nil
+}
+
+// reportAugmenterDrops marks as dropped the converted annotations the augmenter dropped, as their concept was not found.
+func reportAugmenterDrops(conversions []mapper.Conversion, dropped []annotations.Annotation) {
+	droppedKeys := make(map[annotations.Annotation]struct{}, len(dropped))
+	for _, ann := range dropped {
+		droppedKeys[annotations.Annotation{Predicate: ann.Predicate, ConceptId: extractConceptUUID(ann.ConceptId)}] = struct{}{}
+	}
+	for i, c := range conversions {
+		if c.Action != mapper.ConversionConverted {
+			continue
+		}
+		key := annotations.Annotation{Predicate: c.ConvertedPredicate, ConceptId: extractConceptUUID(c.ConvertedID)}
+		if _, found := droppedKeys[key]; found {
+			conversions[i] = mapper.Conversion{Index: c.Index, ID: c.ID, Predicate: c.Predicate, Action: mapper.ConversionDropped, Reason: "concept not found"}
+		}
+	}
+}
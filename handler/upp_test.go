@@ -0,0 +1,252 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/annotations"
+	"github.com/Financial-Times/draft-annotations-api/handler"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/husobee/vestigo"
+	"github.com/stretchr/testify/assert"
+)
+
+const uppAnnotationsBody = `{
+	"annotations": [
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/majorMentions",
+			"id": "http://api.ft.com/things/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+			"types": [
+				"http://www.ft.com/ontology/core/Thing",
+				"http://www.ft.com/ontology/concept/Concept",
+				"http://www.ft.com/ontology/Location"
+			],
+			"prefLabel": "United Kingdom"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/implicitlyClassifiedBy",
+			"id": "http://api.ft.com/things/82645c31-4426-4ef5-99c9-9df6e0940c00",
+			"types": [
+				"http://www.ft.com/ontology/core/Thing",
+				"http://www.ft.com/ontology/concept/Concept",
+				"http://www.ft.com/ontology/Topic"
+			],
+			"prefLabel": "World"
+		}
+	]
+}`
+
+func TestSaveUPPAnnotations(t *testing.T) {
+	tests := map[string]struct {
+		query       string
+		contentType string
+	}{
+		"format param": {query: "?format=upp", contentType: "application/json"},
+		"content type": {contentType: handler.ContentTypeUPPAnnotations + "; charset=utf-8"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var written *annotations.Annotations
+			rw := &RWMock{
+				read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+					return nil, "", false, nil
+				},
+				write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+					written = a
+					return "newHash", nil
+				},
+			}
+			aug := &AugmenterMock{
+				augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+					return depletedAnnotations, nil
+				},
+			}
+			h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
+			r := vestigo.NewRouter()
+			r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+
+			req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations"+test.query, strings.NewReader(uppAnnotationsBody))
+			req.Header.Set(tidutils.TransactionIDHeader, testTID)
+			req.Header.Set("Content-Type", test.contentType)
+			w := httptest.NewRecorder()
+
+			r.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "newHash", w.Header().Get(annotations.DocumentHashHeader))
+
+			if assert.NotNil(t, written) {
+				assert.Equal(t, []annotations.Annotation{
+					{
+						Predicate: "http://www.ft.com/ontology/annotation/about",
+						ConceptId: "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+					},
+				}, written.Annotations)
+			}
+
+			assert.JSONEq(t, `{
+				"annotations": [
+					{
+						"predicate": "http://www.ft.com/ontology/annotation/about",
+						"id": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471"
+					}
+				],
+				"conversions": [
+					{
+						"index": 0,
+						"id": "http://api.ft.com/things/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+						"predicate": "http://www.ft.com/ontology/annotation/majorMentions",
+						"action": "converted",
+						"convertedId": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+						"convertedPredicate": "http://www.ft.com/ontology/annotation/about",
+						"type": "http://www.ft.com/ontology/Location"
+					},
+					{
+						"index": 1,
+						"id": "http://api.ft.com/things/82645c31-4426-4ef5-99c9-9df6e0940c00",
+						"predicate": "http://www.ft.com/ontology/implicitlyClassifiedBy",
+						"action": "dropped",
+						"reason": "implicit predicate http://www.ft.com/ontology/implicitlyClassifiedBy is not used in PAC"
+					}
+				]
+			}`, w.Body.String())
+		})
+	}
+}
+
+func TestSaveAnnotationsInvalidFormat(t *testing.T) {
+	h := handler.New(&RWMock{}, &AnnotationsAPIMock{}, nil, &AugmenterMock{}, time.Second)
+	r := vestigo.NewRouter()
+	r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+
+	req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations?format=v1", strings.NewReader(uppAnnotationsBody))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var problem handler.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+	assert.Equal(t, handler.CodeInvalidRequest, problem.Code)
+}
+
+func TestSaveUPPAnnotationsBareArray(t *testing.T) {
+	var written *annotations.Annotations
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+			written = a
+			return "newHash", nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), identityAugmenter, time.Second)
+	r := vestigo.NewRouter()
+	r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+
+	var body struct {
+		Annotations json.RawMessage `json:"annotations"`
+	}
+	assert.NoError(t, json.Unmarshal([]byte(uppAnnotationsBody), &body))
+
+	req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", strings.NewReader(string(body.Annotations)))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	req.Header.Set("Content-Type", handler.ContentTypeUPPAnnotations)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, written) {
+		assert.Equal(t, []annotations.Annotation{
+			{
+				Predicate: "http://www.ft.com/ontology/annotation/about",
+				ConceptId: "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+			},
+		}, written.Annotations)
+	}
+}
+
+func TestSaveUPPAnnotationsReportsUnknownConcepts(t *testing.T) {
+	const body = `[
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/mentions",
+			"id": "http://api.ft.com/things/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+			"types": ["http://www.ft.com/ontology/Location"]
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://api.ft.com/things/0a619d71-9af5-3755-90dd-f789b686c67a",
+			"types": ["http://www.ft.com/ontology/Topic"]
+		}
+	]`
+
+	var written *annotations.Annotations
+	rw := &RWMock{
+		read: func(ctx context.Context, contentUUID string) (*annotations.Annotations, string, bool, error) {
+			return nil, "", false, nil
+		},
+		write: func(ctx context.Context, contentUUID string, a *annotations.Annotations, hash string) (string, error) {
+			written = a
+			return "newHash", nil
+		},
+	}
+	aug := &AugmenterMock{
+		augment: func(ctx context.Context, depletedAnnotations []annotations.Annotation) ([]annotations.Annotation, error) {
+			var found []annotations.Annotation
+			for _, ann := range depletedAnnotations {
+				if ann.ConceptId != "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a" {
+					found = append(found, ann)
+				}
+			}
+			return found, nil
+		},
+	}
+	h := handler.New(rw, &AnnotationsAPIMock{}, annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter), aug, time.Second)
+	r := vestigo.NewRouter()
+	r.Put("/drafts/content/:uuid/annotations", h.WriteAnnotations)
+
+	req := httptest.NewRequest("PUT", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", strings.NewReader(body))
+	req.Header.Set(tidutils.TransactionIDHeader, testTID)
+	req.Header.Set("Content-Type", handler.ContentTypeUPPAnnotations)
+	w := httptest.NewRecorder()
+
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	if assert.NotNil(t, written) {
+		assert.Len(t, written.Annotations, 1)
+	}
+
+	assert.JSONEq(t, `{
+		"annotations": [
+			{
+				"predicate": "http://www.ft.com/ontology/annotation/mentions",
+				"id": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471"
+			}
+		],
+		"conversions": [
+			{
+				"index": 0,
+				"id": "http://api.ft.com/things/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+				"predicate": "http://www.ft.com/ontology/annotation/mentions",
+				"action": "converted",
+				"convertedId": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+				"convertedPredicate": "http://www.ft.com/ontology/annotation/mentions",
+				"type": "http://www.ft.com/ontology/Location"
+			},
+			{
+				"index": 1,
+				"id": "http://api.ft.com/things/0a619d71-9af5-3755-90dd-f789b686c67a",
+				"predicate": "http://www.ft.com/ontology/annotation/about",
+				"action": "dropped",
+				"reason": "concept not found"
+			}
+		]
+	}`, w.Body.String())
+}
@@ -0,0 +1,162 @@
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// Header is the request header holding the idempotency key chosen by the client.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader is set on the responses replayed from the store.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// Codes of the problems returned for the misused idempotency keys.
+const (
+	CodeKeyReused   = "idempotency_key_reused"
+	CodeKeyInFlight = "idempotency_key_in_flight"
+)
+
+// fingerprintHeaders are the request headers that change the outcome of a write, along with its method, URI and body.
+var fingerprintHeaders = []string{"Content-Type", "Previous-Document-Hash"}
+
+// replayedHeaders are the response headers stored to be replayed.
+var replayedHeaders = []string{"Content-Type", "Document-Hash", "Draft-Written"}
+
+// Middleware replays the stored response to the requests with an idempotency key that has already been processed,
+// so that the retries of a successful write do not fail on a stale document hash.
+// Requests without the Idempotency-Key header are processed as usual, and so are the retries of the requests
+// that failed with a server error or a conflict, e.g. a stale document hash, as their responses are not stored.
+func Middleware(store Store, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tID := r.Header.Get(tidutils.TransactionIDHeader)
+		keyLog := log.WithField(tidutils.TransactionIDKey, tID).WithField("idempotencyKey", key)
+
+		fingerprint, err := requestFingerprint(r)
+		if err != nil {
+			keyLog.WithError(err).Warn("Unable to read the request body, processing it without idempotency")
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		record, reserved, err := store.Reserve(key, fingerprint)
+		if err != nil {
+			keyLog.WithError(err).Warn("Unable to reserve the idempotency key, processing the request without idempotency")
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !reserved {
+			switch {
+			case record.Fingerprint != fingerprint:
+				writeProblem(w, http.StatusUnprocessableEntity, CodeKeyReused, "The idempotency key has already been used for a different request", tID)
+			case record.Response == nil:
+				writeProblem(w, http.StatusConflict, CodeKeyInFlight, "A request with the same idempotency key is being processed", tID)
+			default:
+				keyLog.Info("Replaying the stored response of a duplicate request")
+				replay(w, record.Response)
+			}
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		if rec.status >= http.StatusInternalServerError || rec.status == http.StatusConflict {
+			if err := store.Release(key); err != nil {
+				keyLog.WithError(err).Error("Unable to release the idempotency key")
+			}
+			return
+		}
+		if err := store.Save(key, rec.response()); errors.Is(err, ErrResponseTooLarge) {
+			keyLog.Warn("The response is too large to be stored, duplicates of the request will be processed again")
+		} else if err != nil {
+			keyLog.WithError(err).Error("Unable to store the response for the idempotency key")
+		}
+	})
+}
+
+// requestFingerprint hashes what identifies a write request, leaving its body available to be read again.
+func requestFingerprint(r *http.Request) (string, error) {
+	var body []byte
+	if r.Body != nil {
+		b, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		body = b
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	for _, name := range fingerprintHeaders {
+		h.Write([]byte(name + ": " + r.Header.Get(name) + "\n"))
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func replay(w http.ResponseWriter, response *Response) {
+	for name, values := range response.Header {
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(response.Status)
+	if _, err := w.Write(response.Body); err != nil {
+		log.WithError(err).Error("Failed to write the replayed response")
+	}
+}
+
+func writeProblem(w http.ResponseWriter, status int, code string, detail string, tID string) {
+	p := problem.New(status, code, detail)
+	p.TransactionID = tID
+	problem.Write(w, p)
+}
+
+// recorder keeps a copy of the response written by the handler.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) response() *Response {
+	header := make(http.Header)
+	for _, name := range replayedHeaders {
+		if v := r.Header().Get(name); v != "" {
+			header.Set(name, v)
+		}
+	}
+	return &Response{Status: r.status, Header: header, Body: r.body.Bytes()}
+}
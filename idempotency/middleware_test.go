@@ -0,0 +1,154 @@
+package idempotency
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingHandler struct {
+	calls   int
+	status  int
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (h *countingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.calls++
+	if h.block != nil {
+		close(h.started)
+		<-h.block
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Document-Hash", "hash-"+string(body))
+	w.Header().Set("X-Not-Replayed", "true")
+	w.WriteHeader(h.status)
+	w.Write([]byte(`{"calls":` + string(rune('0'+h.calls)) + `}`))
+}
+
+func newWriteRequest(key string, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/drafts/content/83a201c6-60cd-11e7-91a7-502f7ee26895/annotations", strings.NewReader(body))
+	req.Header.Set(tidutils.TransactionIDHeader, "tid_test")
+	if key != "" {
+		req.Header.Set(Header, key)
+	}
+	return req
+}
+
+func TestMiddlewareReplaysDuplicates(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, 0), next)
+
+	first := httptest.NewRecorder()
+	m.ServeHTTP(first, newWriteRequest("key", "a"))
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, `{"calls":1}`, first.Body.String())
+	assert.Empty(t, first.Header().Get(ReplayedHeader))
+
+	retry := httptest.NewRecorder()
+	m.ServeHTTP(retry, newWriteRequest("key", "a"))
+	assert.Equal(t, 1, next.calls, "the duplicate should not be processed")
+	assert.Equal(t, http.StatusOK, retry.Code)
+	assert.Equal(t, `{"calls":1}`, retry.Body.String())
+	assert.Equal(t, "hash-a", retry.Header().Get("Document-Hash"))
+	assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+	assert.Equal(t, "true", retry.Header().Get(ReplayedHeader))
+	assert.Empty(t, retry.Header().Get("X-Not-Replayed"))
+}
+
+func TestMiddlewareWithoutKey(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, 0), next)
+
+	m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("", "a"))
+	m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("", "a"))
+	assert.Equal(t, 2, next.calls)
+}
+
+func TestMiddlewareRejectsReusedKey(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, 0), next)
+
+	m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("key", "a"))
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, newWriteRequest("key", "b"))
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, CodeKeyReused, p.Code)
+	assert.Equal(t, "tid_test", p.TransactionID)
+}
+
+func TestMiddlewareRejectsInFlightDuplicate(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK, started: make(chan struct{}), block: make(chan struct{})}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, 0), next)
+
+	done := make(chan struct{})
+	go func() {
+		m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("key", "a"))
+		close(done)
+	}()
+	<-next.started
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, newWriteRequest("key", "a"))
+	close(next.block)
+	<-done
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var p problem.Problem
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&p))
+	assert.Equal(t, CodeKeyInFlight, p.Code)
+}
+
+func TestMiddlewareDoesNotStoreServerErrors(t *testing.T) {
+	next := &countingHandler{status: http.StatusServiceUnavailable}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, 0), next)
+
+	m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("key", "a"))
+	next.status = http.StatusOK
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, newWriteRequest("key", "a"))
+	assert.Equal(t, 2, next.calls, "the retry of a failed request should be processed")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestMiddlewareDoesNotStoreConflicts(t *testing.T) {
+	next := &countingHandler{status: http.StatusConflict}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, 0), next)
+
+	m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("key", "a"))
+	next.status = http.StatusOK
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, newWriteRequest("key", "a"))
+	assert.Equal(t, 2, next.calls, "the retry of a conflicting request should be processed")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+}
+
+func TestMiddlewareDoesNotStoreLargeResponses(t *testing.T) {
+	next := &countingHandler{status: http.StatusOK}
+	m := Middleware(NewInMemoryStore(time.Minute, 0, len(`{"calls":1}`)-1), next)
+
+	m.ServeHTTP(httptest.NewRecorder(), newWriteRequest("key", "a"))
+
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, newWriteRequest("key", "a"))
+	assert.Equal(t, 2, next.calls, "the duplicate of a request with a response too large to be stored should be processed")
+	assert.Empty(t, w.Header().Get(ReplayedHeader))
+}
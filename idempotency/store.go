@@ -0,0 +1,183 @@
+package idempotency
+
+import (
+	"container/heap"
+	"container/list"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Response is a response stored to be replayed to the duplicates of the request it has been written for.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Record is what a Store keeps for an idempotency key.
+// Response is nil while the first request with the key is being processed.
+type Record struct {
+	Fingerprint string
+	Response    *Response
+}
+
+// ErrResponseTooLarge is returned by Save when the response is too large to be stored.
+// The key is forgotten, so that the duplicates of the request are processed again.
+var ErrResponseTooLarge = errors.New("response too large to be stored")
+
+// ErrStoreFull is returned by Reserve when every key kept is in flight, so that none of them can be forgotten.
+var ErrStoreFull = errors.New("every idempotency key kept is in flight")
+
+// Store keeps the records of the idempotency keys for the window it has been created with.
+type Store interface {
+	// Reserve records the key for a request with the given fingerprint and returns true,
+	// unless the key is already known, in which case its record is returned instead.
+	Reserve(key string, fingerprint string) (*Record, bool, error)
+	// Save stores the response of the request the key has been reserved for.
+	// Responses too large to be stored are not, the key being released, and ErrResponseTooLarge is returned.
+	Save(key string, response *Response) error
+	// Release forgets the key, so that the request it has been reserved for can be retried.
+	Release(key string) error
+}
+
+type storedRecord struct {
+	key     string
+	record  Record
+	expires time.Time
+	// heapIndex is the position of the record in the expiry heap.
+	heapIndex int
+	// lru is the element of the record in the recency list, nil while the record is in flight.
+	lru *list.Element
+}
+
+// expiryHeap orders the records by expiry, the first to expire on top.
+type expiryHeap []*storedRecord
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expires.Before(h[j].expires) }
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].heapIndex = i
+	h[j].heapIndex = j
+}
+
+func (h *expiryHeap) Push(x interface{}) {
+	r := x.(*storedRecord)
+	r.heapIndex = len(*h)
+	*h = append(*h, r)
+}
+
+func (h *expiryHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return r
+}
+
+type inMemoryStore struct {
+	sync.Mutex
+	window          time.Duration
+	maxKeys         int
+	maxResponseSize int
+	records         map[string]*storedRecord
+	expiries        expiryHeap
+	// recency lists the keys with a stored response from the most to the least recently used.
+	// The keys in flight are not listed, so that they are never forgotten before their request completes.
+	recency *list.List
+	now     func() time.Time
+}
+
+// NewInMemoryStore returns a Store keeping the records in memory, which are lost when the service restarts
+// and are not shared between instances.
+// At most maxKeys keys are kept, the least recently used being forgotten first but never those in flight,
+// and the responses whose body is larger than maxResponseSize bytes are not stored.
+// A non-positive limit disables it.
+func NewInMemoryStore(window time.Duration, maxKeys int, maxResponseSize int) Store {
+	return &inMemoryStore{
+		window:          window,
+		maxKeys:         maxKeys,
+		maxResponseSize: maxResponseSize,
+		records:         make(map[string]*storedRecord),
+		recency:         list.New(),
+		now:             time.Now,
+	}
+}
+
+func (s *inMemoryStore) Reserve(key string, fingerprint string) (*Record, bool, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	now := s.now()
+	s.removeExpired(now)
+
+	if r, found := s.records[key]; found {
+		if r.lru != nil {
+			s.recency.MoveToFront(r.lru)
+		}
+		record := r.record
+		return &record, false, nil
+	}
+
+	if s.maxKeys > 0 && len(s.records) >= s.maxKeys {
+		lru := s.recency.Back()
+		if lru == nil {
+			return nil, false, ErrStoreFull
+		}
+		s.remove(lru.Value.(*storedRecord))
+	}
+	r := &storedRecord{key: key, record: Record{Fingerprint: fingerprint}, expires: now.Add(s.window)}
+	heap.Push(&s.expiries, r)
+	s.records[key] = r
+	return nil, true, nil
+}
+
+func (s *inMemoryStore) Save(key string, response *Response) error {
+	s.Lock()
+	defer s.Unlock()
+
+	r, found := s.records[key]
+	if !found {
+		return nil
+	}
+	if s.maxResponseSize > 0 && len(response.Body) > s.maxResponseSize {
+		s.remove(r)
+		return ErrResponseTooLarge
+	}
+	r.record.Response = response
+	r.expires = s.now().Add(s.window)
+	heap.Fix(&s.expiries, r.heapIndex)
+	if r.lru == nil {
+		r.lru = s.recency.PushFront(r)
+	} else {
+		s.recency.MoveToFront(r.lru)
+	}
+	return nil
+}
+
+func (s *inMemoryStore) Release(key string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if r, found := s.records[key]; found {
+		s.remove(r)
+	}
+	return nil
+}
+
+// removeExpired forgets the expired records, which are on top of the expiry heap.
+func (s *inMemoryStore) removeExpired(now time.Time) {
+	for len(s.expiries) > 0 && !now.Before(s.expiries[0].expires) {
+		s.remove(s.expiries[0])
+	}
+}
+
+func (s *inMemoryStore) remove(r *storedRecord) {
+	heap.Remove(&s.expiries, r.heapIndex)
+	if r.lru != nil {
+		s.recency.Remove(r.lru)
+	}
+	delete(s.records, r.key)
+}
@@ -0,0 +1,130 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryStore(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewInMemoryStore(time.Minute, 0, 0).(*inMemoryStore)
+	store.now = func() time.Time { return now }
+
+	record, reserved, err := store.Reserve("key", "fingerprint")
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Nil(t, record)
+
+	record, reserved, err = store.Reserve("key", "fingerprint")
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, &Record{Fingerprint: "fingerprint"}, record, "the key should be in flight")
+
+	response := &Response{Status: http.StatusOK, Header: http.Header{"Document-Hash": []string{"hash"}}, Body: []byte("{}")}
+	assert.NoError(t, store.Save("key", response))
+
+	record, reserved, err = store.Reserve("key", "other fingerprint")
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Equal(t, &Record{Fingerprint: "fingerprint", Response: response}, record)
+
+	now = now.Add(time.Minute)
+	_, reserved, err = store.Reserve("key", "other fingerprint")
+	assert.NoError(t, err)
+	assert.True(t, reserved, "the key should have expired")
+}
+
+func TestInMemoryStoreRelease(t *testing.T) {
+	store := NewInMemoryStore(time.Minute, 0, 0)
+
+	_, reserved, _ := store.Reserve("key", "fingerprint")
+	assert.True(t, reserved)
+	assert.NoError(t, store.Release("key"))
+
+	_, reserved, _ = store.Reserve("key", "fingerprint")
+	assert.True(t, reserved, "a released key should be reserved again")
+}
+
+func TestInMemoryStoreEvictsLeastRecentlyUsedKeys(t *testing.T) {
+	store := NewInMemoryStore(time.Minute, 2, 0).(*inMemoryStore)
+
+	store.Reserve("first", "fingerprint")
+	store.Reserve("second", "fingerprint")
+	assert.NoError(t, store.Save("first", &Response{Status: http.StatusOK}))
+	assert.NoError(t, store.Save("second", &Response{Status: http.StatusOK}))
+	_, reserved, _ := store.Reserve("first", "fingerprint")
+	assert.False(t, reserved)
+
+	_, reserved, _ = store.Reserve("third", "fingerprint")
+	assert.True(t, reserved)
+	assert.Len(t, store.records, 2)
+
+	_, reserved, _ = store.Reserve("first", "fingerprint")
+	assert.False(t, reserved, "the recently used key should be kept")
+	_, reserved, _ = store.Reserve("second", "fingerprint")
+	assert.True(t, reserved, "the least recently used key should have been forgotten")
+}
+
+func TestInMemoryStoreNeverEvictsKeysInFlight(t *testing.T) {
+	store := NewInMemoryStore(time.Minute, 2, 0).(*inMemoryStore)
+
+	store.Reserve("in flight", "fingerprint")
+	store.Reserve("done", "fingerprint")
+	assert.NoError(t, store.Save("done", &Response{Status: http.StatusOK}))
+	store.Reserve("in flight", "fingerprint")
+
+	_, reserved, err := store.Reserve("other", "fingerprint")
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Contains(t, store.records, "in flight", "the key in flight should be kept, even if least recently used")
+	assert.NotContains(t, store.records, "done")
+
+	_, reserved, err = store.Reserve("another", "fingerprint")
+	assert.True(t, errors.Is(err, ErrStoreFull), "no key can be forgotten while they are all in flight")
+	assert.False(t, reserved)
+	assert.Len(t, store.records, 2)
+}
+
+func TestInMemoryStoreExpiresKeysInOrder(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewInMemoryStore(time.Minute, 0, 0).(*inMemoryStore)
+	store.now = func() time.Time { return now }
+
+	store.Reserve("first", "fingerprint")
+	now = now.Add(30 * time.Second)
+	store.Reserve("second", "fingerprint")
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, store.Save("first", &Response{Status: http.StatusOK}))
+
+	now = now.Add(50 * time.Second)
+	store.Reserve("third", "fingerprint")
+	assert.Contains(t, store.records, "first", "saving the response should have extended the window")
+	assert.NotContains(t, store.records, "second")
+	assert.Len(t, store.expiries, 2)
+
+	now = now.Add(10 * time.Second)
+	store.Reserve("fourth", "fingerprint")
+	assert.NotContains(t, store.records, "first")
+	assert.Len(t, store.records, 2)
+	assert.Zero(t, store.recency.Len(), "the keys in flight should not be listed by recency")
+}
+
+func TestInMemoryStoreDoesNotStoreLargeResponses(t *testing.T) {
+	store := NewInMemoryStore(time.Minute, 0, 2)
+
+	store.Reserve("key", "fingerprint")
+	err := store.Save("key", &Response{Status: http.StatusOK, Body: []byte("{ }")})
+	assert.True(t, errors.Is(err, ErrResponseTooLarge))
+
+	_, reserved, _ := store.Reserve("key", "fingerprint")
+	assert.True(t, reserved, "the key of a response too large to be stored should be forgotten")
+
+	assert.NoError(t, store.Save("key", &Response{Status: http.StatusOK, Body: []byte("{}")}))
+	record, reserved, _ := store.Reserve("key", "fingerprint")
+	assert.False(t, reserved)
+	assert.Equal(t, []byte("{}"), record.Response.Body)
+}
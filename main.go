@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"os"
 	"time"
@@ -8,8 +10,13 @@ import (
 	api "github.com/Financial-Times/api-endpoint"
 	"github.com/Financial-Times/draft-annotations-api/annotations"
 	"github.com/Financial-Times/draft-annotations-api/concept"
+	"github.com/Financial-Times/draft-annotations-api/fixtures"
 	"github.com/Financial-Times/draft-annotations-api/handler"
 	"github.com/Financial-Times/draft-annotations-api/health"
+	"github.com/Financial-Times/draft-annotations-api/idempotency"
+	"github.com/Financial-Times/draft-annotations-api/mapper"
+	"github.com/Financial-Times/draft-annotations-api/openapi"
+	"github.com/Financial-Times/draft-annotations-api/suggestion"
 	"github.com/Financial-Times/go-ft-http/fthttp"
 	"github.com/Financial-Times/http-handlers-go/httphandlers"
 	status "github.com/Financial-Times/service-status-go/httphandlers"
@@ -21,6 +28,19 @@ import (
 
 const appDescription = "PAC Draft Annotations API"
 
+type annotationsAPI interface {
+	handler.AnnotationsAPI
+	Endpoint() string
+	GTG() error
+}
+
+type services struct {
+	rw             annotations.RW
+	annotationsAPI annotationsAPI
+	conceptRead    concept.ReadAPI
+	handler        *handler.Handler
+}
+
 func main() {
 	app := cli.App("draft-annotations-api", appDescription)
 
@@ -42,12 +62,42 @@ func main() {
 		Desc:   "Port to listen on",
 		EnvVar: "APP_PORT",
 	})
+	opsPort := app.String(cli.StringOpt{
+		Name:   "ops-port",
+		Value:  "8081",
+		Desc:   "Port to listen on for the /__admin operations, which must not be exposed through the public ingresses",
+		EnvVar: "OPS_PORT",
+	})
 	annotationsRWEndpoint := app.String(cli.StringOpt{
 		Name:   "annotations-rw-endpoint",
 		Value:  "http://localhost:8888",
 		Desc:   "Endpoint to get draft annotations from DB",
 		EnvVar: "ANNOTATIONS_RW_ENDPOINT",
 	})
+	annotationsRWBackend := app.String(cli.StringOpt{
+		Name:   "annotations-rw-backend",
+		Value:  "http",
+		Desc:   "Storage backend for draft annotations: http (Generic RW Aurora at annotations-rw-endpoint), memory or sqlite",
+		EnvVar: "ANNOTATIONS_RW_BACKEND",
+	})
+	annotationsRWSQLitePath := app.String(cli.StringOpt{
+		Name:   "annotations-rw-sqlite-path",
+		Value:  "./draft-annotations.db",
+		Desc:   "Location of the SQLite database file used by the sqlite storage backend",
+		EnvVar: "ANNOTATIONS_RW_SQLITE_PATH",
+	})
+	conceptIndexBackend := app.String(cli.StringOpt{
+		Name:   "concept-index-backend",
+		Value:  "sqlite",
+		Desc:   "Storage backend for the index of the drafts referencing each concept: sqlite, or memory for local runs and tests only",
+		EnvVar: "CONCEPT_INDEX_BACKEND",
+	})
+	conceptIndexSQLitePath := app.String(cli.StringOpt{
+		Name:   "concept-index-sqlite-path",
+		Value:  "./concept-index.db",
+		Desc:   "Location of the SQLite database file used by the sqlite concept index backend",
+		EnvVar: "CONCEPT_INDEX_SQLITE_PATH",
+	})
 	annotationsAPIEndpoint := app.String(cli.StringOpt{
 		Name:   "upp-annotations-endpoint",
 		Value:  "http://test.api.ft.com/content/%v/annotations",
@@ -60,12 +110,60 @@ func main() {
 		Desc:   "Endpoint to get concepts from UPP",
 		EnvVar: "INTERNAL_CONCORDANCES_ENDPOINT",
 	})
+	suggestionsEndpoint := app.String(cli.StringOpt{
+		Name:   "suggestions-endpoint",
+		Value:  "",
+		Desc:   "Suggestion service endpoint to get the concepts suggested for a content. Suggestions are disabled when empty",
+		EnvVar: "SUGGESTIONS_ENDPOINT",
+	})
+	suggestionsAPIKey := app.String(cli.StringOpt{
+		Name:   "suggestions-api-key",
+		Value:  "",
+		Desc:   "API key to access the suggestion service",
+		EnvVar: "SUGGESTIONS_API_KEY",
+	})
+	conceptSearchEndpoint := app.String(cli.StringOpt{
+		Name:   "concept-search-endpoint",
+		Value:  "",
+		Desc:   "Concept search endpoint, called with the searched text as q and the searched types as type. Concept search is disabled when empty",
+		EnvVar: "CONCEPT_SEARCH_ENDPOINT",
+	})
 	internalConcordancesBatchSize := app.Int(cli.IntOpt{
 		Name:   "internal-concordances-batch-size",
 		Value:  30,
 		Desc:   "Concept IDs maximum batch size to use when querying the UPP Internal Concordances API",
 		EnvVar: "INTERNAL_CONCORDANCES_BATCH_SIZE",
 	})
+	internalConcordancesBatchWindow := app.String(cli.StringOpt{
+		Name:   "internal-concordances-batch-window",
+		Value:  "5ms",
+		Desc:   "Duration the concept IDs requested by concurrent requests are collected for, to query the UPP Internal Concordances API in combined batches",
+		EnvVar: "INTERNAL_CONCORDANCES_BATCH_WINDOW",
+	})
+	lastKnownConceptsSize := app.Int(cli.IntOpt{
+		Name:   "last-known-concepts-size",
+		Value:  10000,
+		Desc:   "Number of concepts whose last known data is kept to augment the annotations when the UPP Internal Concordances API is unavailable",
+		EnvVar: "LAST_KNOWN_CONCEPTS_SIZE",
+	})
+	degradedWrites := app.Bool(cli.BoolOpt{
+		Name:   "degraded-writes",
+		Value:  false,
+		Desc:   "Allow the writes with the last known concept data when the UPP Internal Concordances API is unavailable, instead of rejecting them",
+		EnvVar: "DEGRADED_WRITES",
+	})
+	localFixtures := app.Bool(cli.BoolOpt{
+		Name:   "local-fixtures",
+		Value:  false,
+		Desc:   "Serve UPP annotations and internal concordances from the local fixtures file instead of calling UPP",
+		EnvVar: "LOCAL_FIXTURES",
+	})
+	localFixturesFile := app.String(cli.StringOpt{
+		Name:   "local-fixtures-file",
+		Value:  "./_ft/ersatz-fixtures.yml",
+		Desc:   "Location of the ersatz fixtures file used in local fixtures mode",
+		EnvVar: "LOCAL_FIXTURES_FILE",
+	})
 	uppAPIKey := app.String(cli.StringOpt{
 		Name:   "upp-api-key",
 		Value:  "",
@@ -78,12 +176,78 @@ func main() {
 		Desc:   "Location of the API Swagger YML file.",
 		EnvVar: "API_YML",
 	})
+	validateRequests := app.Bool(cli.BoolOpt{
+		Name:   "validate-requests",
+		Value:  true,
+		Desc:   "Reject the requests that do not match the API Swagger YML and check at startup that it describes all the registered routes",
+		EnvVar: "VALIDATE_REQUESTS",
+	})
 	httpTimeoutDuration := app.String(cli.StringOpt{
 		Name:   "http-timeout",
 		Value:  "8s",
 		Desc:   "Duration to wait before timing out a request",
 		EnvVar: "HTTP_TIMEOUT",
 	})
+	readCacheTTL := app.String(cli.StringOpt{
+		Name:   "read-cache-ttl",
+		Value:  "0s",
+		Desc:   "Duration the annotations read are cached for, until written through this instance. Disabled with 0s",
+		EnvVar: "READ_CACHE_TTL",
+	})
+	publishedCacheTTL := app.String(cli.StringOpt{
+		Name:   "published-annotations-cache-ttl",
+		Value:  "0s",
+		Desc:   "Duration the published annotations from the UPP Public Annotations API are cached for. Disabled with 0s",
+		EnvVar: "PUBLISHED_ANNOTATIONS_CACHE_TTL",
+	})
+	annotationLifecycles := app.Strings(cli.StringsOpt{
+		Name:   "annotation-lifecycles",
+		Value:  annotations.DefaultLifecycles,
+		Desc:   "Lifecycles of the published annotations returned when the content has no draft",
+		EnvVar: "ANNOTATION_LIFECYCLES",
+	})
+	editableLifecycles := app.Strings(cli.StringsOpt{
+		Name:   "editable-lifecycles",
+		Value:  annotations.DefaultEditableLifecycles,
+		Desc:   "Lifecycles of the editorially curated published annotations the annotation edits start from",
+		EnvVar: "EDITABLE_LIFECYCLES",
+	})
+	migrateMergedConcepts := app.Bool(cli.BoolOpt{
+		Name:   "migrate-merged-concepts",
+		Value:  false,
+		Desc:   "Rewrite stored drafts with the canonical concept IDs when merged concepts are found on read",
+		EnvVar: "MIGRATE_MERGED_CONCEPTS",
+	})
+	idempotencyWindow := app.String(cli.StringOpt{
+		Name:   "idempotency-window",
+		Value:  "24h",
+		Desc:   "Duration the responses to the write requests with an Idempotency-Key header are replayed for",
+		EnvVar: "IDEMPOTENCY_WINDOW",
+	})
+	idempotencyMaxKeys := app.Int(cli.IntOpt{
+		Name:   "idempotency-max-keys",
+		Value:  10000,
+		Desc:   "Number of idempotency keys kept, the least recently used being forgotten first",
+		EnvVar: "IDEMPOTENCY_MAX_KEYS",
+	})
+	idempotencyMaxResponseSize := app.Int(cli.IntOpt{
+		Name:   "idempotency-max-response-size",
+		Value:  1 << 20,
+		Desc:   "Size in bytes of the largest response body stored to be replayed for an idempotency key",
+		EnvVar: "IDEMPOTENCY_MAX_RESPONSE_SIZE",
+	})
+	predicatePrecedence := app.Strings(cli.StringsOpt{
+		Name:   "predicate-precedence",
+		Value:  handler.DefaultPredicatePrecedence,
+		Desc:   "Predicates from the strongest to the weakest, keeping only the strongest when a concept replacement leaves a concept annotated with several of them",
+		EnvVar: "PREDICATE_PRECEDENCE",
+	})
+	strictWrites := app.Bool(cli.BoolOpt{
+		Name:   "strict-writes",
+		Value:  false,
+		Desc:   "Reject the PUT requests with invalid predicates or concept IDs instead of dropping the invalid annotations",
+		EnvVar: "STRICT_WRITES",
+	})
 	logLevel := app.String(cli.StringOpt{
 		Name:   "log-level",
 		Value:  "INFO",
@@ -94,9 +258,7 @@ func main() {
 	log.SetFormatter(&log.JSONFormatter{})
 	log.Infof("[Startup] %v is starting", *appSystemCode)
 
-	app.Action = func() {
-		log.Infof("System code: %s, App Name: %s, Port: %s", *appSystemCode, *appName, *port)
-
+	setup := func() *services {
 		// Setting the real log level here in order to have the startup log
 		parsedLogLevel, err := log.ParseLevel(*logLevel)
 		if err != nil {
@@ -112,15 +274,210 @@ func main() {
 
 		client := fthttp.NewClientWithDefaultTimeout("PAC", *appSystemCode)
 
-		rw := annotations.NewRW(client, *annotationsRWEndpoint)
-		annotationsAPI := annotations.NewUPPAnnotationsAPI(client, *annotationsAPIEndpoint, *uppAPIKey)
-		c14n := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
+		var rw annotations.RW
+		switch *annotationsRWBackend {
+		case "http":
+			rw = annotations.NewRW(client, *annotationsRWEndpoint)
+		case "memory":
+			rw = annotations.NewInMemoryRW()
+		case "sqlite":
+			rw, err = annotations.NewSQLiteRW(*annotationsRWSQLitePath)
+			if err != nil {
+				log.WithError(err).Fatal("Unable to open the SQLite storage backend")
+			}
+		default:
+			log.WithField("backend", *annotationsRWBackend).Fatal("Please provide a valid annotations RW backend")
+		}
+		if len(*annotationLifecycles) == 0 || len(*editableLifecycles) == 0 {
+			log.Fatal("Please provide at least one annotation lifecycle and one editable lifecycle")
+		}
+		var annotationsAPI annotationsAPI = annotations.NewUPPAnnotationsAPI(client, *annotationsAPIEndpoint, *uppAPIKey,
+			annotations.WithLifecycles(*annotationLifecycles), annotations.WithEditableLifecycles(*editableLifecycles))
 		conceptRead := concept.NewReadAPI(client, *internalConcordancesEndpoint, *uppAPIKey, *internalConcordancesBatchSize)
-		augmenter := annotations.NewAugmenter(conceptRead)
-		annotationsHandler := handler.New(rw, annotationsAPI, c14n, augmenter, time.Millisecond*httpTimeout)
-		healthService := health.NewHealthService(*appSystemCode, *appName, appDescription, rw, annotationsAPI, conceptRead)
+		var suggestionAPI suggestion.API
+		if *suggestionsEndpoint != "" {
+			suggestionAPI = suggestion.NewAPI(client, *suggestionsEndpoint, *suggestionsAPIKey)
+		}
+		var conceptSearch concept.SearchAPI
+		if *conceptSearchEndpoint != "" {
+			conceptSearch = concept.NewSearchAPI(client, *conceptSearchEndpoint, *uppAPIKey)
+		}
+		if *localFixtures {
+			f, err := fixtures.Load(*localFixturesFile)
+			if err != nil {
+				log.WithError(err).WithField("file", *localFixturesFile).Fatal("Unable to load local fixtures")
+			}
+			annotationsAPI = f.AnnotationsAPI()
+			conceptRead = f.ConceptReadAPI()
+			suggestionAPI = f.SuggestionAPI()
+			conceptSearch = f.ConceptSearchAPI()
+			if *annotationsRWBackend != "http" {
+				if err := f.SeedRW(context.Background(), rw); err != nil {
+					log.WithError(err).Fatal("Unable to seed draft annotations from local fixtures")
+				}
+			}
+			log.WithField("file", *localFixturesFile).Info("Serving UPP annotations and concepts from local fixtures")
+		}
+		var conceptIndex annotations.ConceptIndex
+		if _, ok := rw.(annotations.DraftLister); ok {
+			switch *conceptIndexBackend {
+			case "memory":
+				conceptIndex = annotations.NewInMemoryConceptIndex()
+			case "sqlite":
+				conceptIndex, err = annotations.NewSQLiteConceptIndex(*conceptIndexSQLitePath)
+				if err != nil {
+					log.WithError(err).Fatal("Unable to open the SQLite concept index backend")
+				}
+			default:
+				log.WithField("backend", *conceptIndexBackend).Fatal("Please provide a valid concept index backend")
+			}
+			indexed, err := annotations.RebuildConceptIndex(context.Background(), conceptIndex, rw, nil)
+			if err != nil {
+				log.WithError(err).Fatal("Unable to build the concept index from the draft annotations")
+			}
+			log.WithField("indexed", indexed).Info("Concept index built from the draft annotations")
+		} else {
+			// The index could never be completely built without listing the drafts, so it is not kept at all.
+			log.WithField("backend", *annotationsRWBackend).Info("The annotations RW cannot list its drafts, the drafts referencing a concept are unavailable")
+		}
+		publishedTTL, err := time.ParseDuration(*publishedCacheTTL)
+		if err != nil {
+			log.WithError(err).Fatal("Please provide a valid published annotations cache duration")
+		}
+		if publishedTTL > 0 {
+			annotationsAPI = annotations.NewCachedPublishedAPI(annotationsAPI, publishedTTL)
+		}
+		c14n := annotations.NewCanonicalizer(annotations.NewCanonicalAnnotationSorter)
+		batchWindow, err := time.ParseDuration(*internalConcordancesBatchWindow)
+		if err != nil {
+			log.WithError(err).Fatal("Please provide a valid internal concordances batch window duration")
+		}
+		conceptLoader := concept.NewLoader(conceptRead, batchWindow, *internalConcordancesBatchSize)
+		lastKnownConcepts := concept.NewLastKnownCache(conceptLoader, *lastKnownConceptsSize)
+		augmenter := annotations.NewAugmenter(lastKnownConcepts)
+		handlerOpts := []handler.Option{handler.WithDegradedReads(annotations.NewDegradedAugmenter(lastKnownConcepts))}
+		if *degradedWrites {
+			handlerOpts = append(handlerOpts, handler.WithDegradedWrites())
+		}
+		cacheTTL, err := time.ParseDuration(*readCacheTTL)
+		if err != nil {
+			log.WithError(err).Fatal("Please provide a valid read cache duration")
+		}
+		handlerOpts = append(handlerOpts, handler.WithReadCache(cacheTTL), handler.WithMetricsRegistry(metrics.DefaultRegistry))
+		if *migrateMergedConcepts {
+			handlerOpts = append(handlerOpts, handler.WithMergedConceptsMigration())
+		}
+		if *strictWrites {
+			handlerOpts = append(handlerOpts, handler.WithStrictWrites())
+		}
+		for _, p := range *predicatePrecedence {
+			if !mapper.IsValidPACPredicate(p) {
+				log.WithField("predicate", p).Fatal("Please provide valid PAC predicates as predicate precedence")
+			}
+		}
+		handlerOpts = append(handlerOpts, handler.WithPredicatePrecedence(*predicatePrecedence))
+		handlerOpts = append(handlerOpts, handler.WithMachineLifecycles(machineLifecycles(*annotationLifecycles, *editableLifecycles)))
+		if suggestionAPI != nil {
+			log.WithField("endpoint", suggestionAPI.Endpoint()).Info("Serving annotation suggestions")
+			handlerOpts = append(handlerOpts, handler.WithSuggester(suggestionAPI))
+		}
+		if conceptSearch != nil {
+			log.WithField("endpoint", conceptSearch.Endpoint()).Info("Serving concept search")
+			handlerOpts = append(handlerOpts, handler.WithConceptSearch(conceptSearch))
+		}
+		if conceptIndex != nil {
+			log.WithField("endpoint", conceptIndex.Endpoint()).Info("Indexing the concepts referenced by the drafts")
+			handlerOpts = append(handlerOpts, handler.WithConceptIndex(conceptIndex))
+		}
+		annotationsHandler := handler.New(rw, annotationsAPI, c14n, augmenter, httpTimeout, handlerOpts...)
 
-		serveEndpoints(*port, apiYml, annotationsHandler, healthService)
+		return &services{
+			rw:             rw,
+			annotationsAPI: annotationsAPI,
+			conceptRead:    conceptRead,
+			handler:        annotationsHandler,
+		}
+	}
+
+	app.Command("replace-concepts", "Replace or remove concepts across the draft annotations of many pieces of content", func(cmd *cli.Cmd) {
+		conceptMappings := cmd.Strings(cli.StringsOpt{
+			Name: "concept",
+			Desc: "Concept ID mapping in the form OLD=NEW, the concept is removed if NEW is empty",
+		})
+		contentUUIDs := cmd.Strings(cli.StringsOpt{
+			Name: "content-uuid",
+			Desc: "UUID of the content whose draft annotations are updated",
+		})
+		contentFile := cmd.String(cli.StringOpt{
+			Name: "content-file",
+			Desc: "File listing the UUIDs of the content whose draft annotations are updated, one per line",
+		})
+		dryRun := cmd.Bool(cli.BoolOpt{
+			Name:  "dry-run",
+			Value: true,
+			Desc:  "Report the changes without writing the draft annotations",
+		})
+		progressLogFile := cmd.String(cli.StringOpt{
+			Name:  "progress-log",
+			Value: "replace-concepts.progress",
+			Desc:  "File recording the drafts processed with each concept mapping, used to resume an interrupted run",
+		})
+
+		cmd.Action = func() {
+			s := setup()
+
+			mapping, err := handler.ParseConceptMapping(*conceptMappings)
+			if err != nil {
+				log.WithError(err).Fatal("Please provide valid concept mappings")
+			}
+
+			uuids := *contentUUIDs
+			if *contentFile != "" {
+				f, err := os.Open(*contentFile)
+				if err != nil {
+					log.WithError(err).Fatal("Unable to open content UUIDs file")
+				}
+				fileUUIDs, err := handler.ReadContentUUIDs(f)
+				f.Close()
+				if err != nil {
+					log.WithError(err).Fatal("Unable to read content UUIDs file")
+				}
+				uuids = append(uuids, fileUUIDs...)
+			}
+
+			progress, err := handler.NewFileProgressLog(*progressLogFile)
+			if err != nil {
+				log.WithError(err).Fatal("Unable to open progress log")
+			}
+			defer progress.Close()
+
+			report, err := s.handler.ReplaceConcepts(context.Background(), mapping, uuids, *dryRun, progress)
+			if err != nil {
+				log.WithError(err).Error("Replacing concepts failed")
+			}
+			if report != nil {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(report); err != nil {
+					log.WithError(err).Error("Failed to write replace concepts report")
+				}
+			}
+		}
+	})
+
+	app.Action = func() {
+		log.Infof("System code: %s, App Name: %s, Port: %s, Ops port: %s", *appSystemCode, *appName, *port, *opsPort)
+
+		s := setup()
+		healthService := health.NewHealthService(*appSystemCode, *appName, appDescription, s.rw, s.annotationsAPI, s.conceptRead)
+
+		window, err := time.ParseDuration(*idempotencyWindow)
+		if err != nil {
+			log.WithError(err).Fatal("Please provide a valid idempotency window duration")
+		}
+		idempotencyStore := idempotency.NewInMemoryStore(window, *idempotencyMaxKeys, *idempotencyMaxResponseSize)
+
+		serveEndpoints(*port, *opsPort, apiYml, *validateRequests, s.handler, healthService, idempotencyStore)
 	}
 
 	err := app.Run(os.Args)
@@ -130,22 +487,79 @@ func main() {
 	}
 }
 
-func serveEndpoints(port string, apiYml *string, handler *handler.Handler, healthService *health.HealthService) {
-	r := vestigo.NewRouter()
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+}
+
+func metricsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	metrics.WriteJSONOnce(metrics.DefaultRegistry, w)
+}
+
+func serveEndpoints(port string, opsPort string, apiYml *string, validateRequests bool, handler *handler.Handler, healthService *health.HealthService, idempotencyStore idempotency.Store) {
+	routes := []route{
+		{http.MethodDelete, "/drafts/content/:uuid/annotations/:cuuid", handler.DeleteAnnotation},
+		{http.MethodGet, "/drafts/content/:uuid/annotations", handler.ReadAnnotations},
+		{http.MethodPut, "/drafts/content/:uuid/annotations", handler.WriteAnnotations},
+		{http.MethodPost, "/drafts/content/:uuid/annotations", handler.AddAnnotation},
+		{http.MethodPost, "/drafts/content/:uuid/annotations/promote", handler.PromoteAnnotations},
+		{http.MethodGet, "/drafts/content/:uuid/annotations/suggestions", handler.GetSuggestions},
+		{http.MethodPatch, "/drafts/content/:uuid/annotations/:cuuid", handler.ReplaceAnnotation},
+		{http.MethodGet, "/drafts/concepts/search", handler.SearchConcepts},
+		{http.MethodGet, "/drafts/concepts/:cuuid/content", handler.GetConceptContent},
+	}
+	// The operations are served on their own port, not exposed through the public ingresses, and outside the idempotency middleware.
+	opsRoutes := []route{
+		{http.MethodPost, "/__admin/concepts/replace", handler.BulkReplaceConcepts},
+		{http.MethodDelete, "/__admin/published-annotations/:uuid", handler.InvalidatePublishedAnnotations},
+		{http.MethodPost, "/__admin/concept-index/rebuild", handler.RebuildConceptIndex},
+	}
+	adminRoutes := []route{
+		{http.MethodGet, "/__health", healthService.HealthCheckHandleFunc()},
+		{http.MethodGet, status.GTGPath, status.NewGoodToGoHandler(healthService.GTG)},
+		{http.MethodGet, status.BuildInfoPath, status.BuildInfoHandler},
+		{http.MethodGet, "/__metrics", metricsHandler},
+	}
 
-	r.Delete("/drafts/content/:uuid/annotations/:cuuid", handler.DeleteAnnotation)
-	r.Get("/drafts/content/:uuid/annotations", handler.ReadAnnotations)
-	r.Put("/drafts/content/:uuid/annotations", handler.WriteAnnotations)
-	r.Post("/drafts/content/:uuid/annotations", handler.AddAnnotation)
-	r.Patch("/drafts/content/:uuid/annotations/:cuuid", handler.ReplaceAnnotation)
+	r := vestigo.NewRouter()
+	for _, rt := range routes {
+		h := rt.handler
+		if rt.method != http.MethodGet {
+			h = idempotency.Middleware(idempotencyStore, h).ServeHTTP
+		}
+		r.Add(rt.method, rt.path, h)
+	}
+	opsRouter := vestigo.NewRouter()
+	for _, rt := range opsRoutes {
+		opsRouter.Add(rt.method, rt.path, rt.handler)
+	}
 
 	var monitoringRouter http.Handler = r
+	var monitoringOpsRouter http.Handler = opsRouter
+	if validateRequests {
+		spec, err := openapi.Load(*apiYml)
+		if err != nil {
+			log.WithError(err).WithField("file", *apiYml).Fatal("Unable to load the API Swagger YML to validate requests")
+		}
+		var registered []openapi.Route
+		for _, rt := range append(append(routes, opsRoutes...), adminRoutes...) {
+			registered = append(registered, openapi.Route{Method: rt.method, Path: rt.path})
+		}
+		if err := spec.CheckRoutes(registered); err != nil {
+			log.WithError(err).WithField("file", *apiYml).Fatal("The API Swagger YML does not describe the registered routes")
+		}
+		monitoringRouter = spec.Middleware(monitoringRouter)
+		monitoringOpsRouter = spec.Middleware(monitoringOpsRouter)
+	}
 	monitoringRouter = httphandlers.TransactionAwareRequestLoggingHandler(log.StandardLogger(), monitoringRouter)
 	monitoringRouter = httphandlers.HTTPMetricsHandler(metrics.DefaultRegistry, monitoringRouter)
+	monitoringOpsRouter = httphandlers.TransactionAwareRequestLoggingHandler(log.StandardLogger(), monitoringOpsRouter)
 
-	http.HandleFunc("/__health", healthService.HealthCheckHandleFunc())
-	http.HandleFunc(status.GTGPath, status.NewGoodToGoHandler(healthService.GTG))
-	http.HandleFunc(status.BuildInfoPath, status.BuildInfoHandler)
+	for _, rt := range adminRoutes {
+		http.HandleFunc(rt.path, rt.handler)
+	}
 
 	http.Handle("/", monitoringRouter)
 
@@ -158,7 +572,28 @@ func serveEndpoints(port string, apiYml *string, handler *handler.Handler, healt
 		}
 	}
 
+	go func() {
+		if err := http.ListenAndServe(":"+opsPort, monitoringOpsRouter); err != nil {
+			log.Fatalf("Unable to start the ops endpoints: %v", err)
+		}
+	}()
+
 	if err := http.ListenAndServe(":"+port, nil); err != nil {
 		log.Fatalf("Unable to start: %v", err)
 	}
 }
+
+// machineLifecycles returns the lifecycles of the annotations which are not editable, i.e. suggested by the machine tagger.
+func machineLifecycles(lifecycles []string, editable []string) []string {
+	isEditable := make(map[string]struct{}, len(editable))
+	for _, lc := range editable {
+		isEditable[lc] = struct{}{}
+	}
+	machine := []string{}
+	for _, lc := range lifecycles {
+		if _, found := isEditable[lc]; !found {
+			machine = append(machine, lc)
+		}
+	}
+	return machine
+}
@@ -30,73 +30,132 @@ const (
 	ConceptTypeSubject       = "http://www.ft.com/ontology/Subject"
 )
 
+// Actions recorded in a Conversion.
+const (
+	ConversionConverted = "converted"
+	ConversionDropped   = "dropped"
+)
+
+// Conversion records what ConvertPredicatesWithReport did with a UPP annotation,
+// identified by its index in the original UPP body. The reason tells why the annotation has been dropped,
+// or what is unexpected about an annotation converted nonetheless.
+type Conversion struct {
+	Index              int    `json:"index"`
+	ID                 string `json:"id,omitempty"`
+	Predicate          string `json:"predicate,omitempty"`
+	Action             string `json:"action"`
+	ConvertedID        string `json:"convertedId,omitempty"`
+	ConvertedPredicate string `json:"convertedPredicate,omitempty"`
+	Type               string `json:"type,omitempty"`
+	Reason             string `json:"reason,omitempty"`
+}
+
 func ConvertPredicates(body []byte) ([]byte, error) {
+	convertedBody, _, err := ConvertPredicatesWithReport(body)
+	return convertedBody, err
+}
+
+// ConvertPredicatesWithReport converts UPP annotations to PAC as ConvertPredicates does,
+// and reports how each of the annotations has been converted or why it has been dropped.
+func ConvertPredicatesWithReport(body []byte) ([]byte, []Conversion, error) {
 	originalAnnotations := make([]map[string]interface{}, 0)
 	convertedAnnotations := make([]map[string]interface{}, 0)
 	err := json.Unmarshal(body, &originalAnnotations)
 	if err != nil {
-		return nil, fmt.Errorf("could not unmarshal json body:%w", err)
+		return nil, nil, fmt.Errorf("could not unmarshal json body:%w", err)
 	}
 
-	for _, annoMap := range originalAnnotations {
-
-		pred, ok := annoMap["predicate"]
-		if !ok {
-			log.Info("no predicate supplied for incoming annotation")
-			continue
+	report := make([]Conversion, 0, len(originalAnnotations))
+	for i, annoMap := range originalAnnotations {
+		c := Conversion{Index: i}
+		c.ID, _ = annoMap["id"].(string)
+		c.Predicate, _ = annoMap["predicate"].(string)
+		if someTypes, ok := annoMap["types"]; ok {
+			// Annotations without valid types are converted without a type, as they have always been.
+			if stringTypes, err := toStringArray(someTypes); err != nil || len(stringTypes) == 0 {
+				c.Reason = "unexpected types property"
+			}
 		}
-		predicate := pred.(string)
-		someTypes, ok := annoMap["types"]
-		if !ok {
-			log.Info("no types supplied for incoming annotation")
+
+		if reason := convertAnnotation(annoMap); reason != "" {
+			c.Action = ConversionDropped
+			c.Reason = reason
+			report = append(report, c)
 			continue
 		}
 
-		annoMap["id"] = TransformConceptID(annoMap["id"].(string))
+		c.Action = ConversionConverted
+		c.ConvertedID, _ = annoMap["id"].(string)
+		c.ConvertedPredicate, _ = annoMap["predicate"].(string)
+		c.Type, _ = annoMap["type"].(string)
+		report = append(report, c)
+		convertedAnnotations = append(convertedAnnotations, annoMap)
+	}
 
-		stringTypes, _ := toStringArray(someTypes)
-		conceptType := getLeafType(stringTypes)
+	if len(convertedAnnotations) == 0 {
+		return nil, report, nil
+	}
 
-		annoMap["type"] = conceptType
-		delete(annoMap, "types")
+	convertedBody, err := json.Marshal(convertedAnnotations)
+	return convertedBody, report, err
+}
 
-		if conceptType == ConceptTypeSpecialReport || conceptType == ConceptTypeSubject {
-			continue
-		}
+// convertAnnotation converts the UPP annotation in place,
+// and returns the reason why it cannot be converted to PAC, if any.
+func convertAnnotation(annoMap map[string]interface{}) string {
+	predicate, ok := annoMap["predicate"].(string)
+	if !ok {
+		log.Info("no predicate supplied for incoming annotation")
+		return "no predicate supplied"
+	}
+	someTypes, ok := annoMap["types"]
+	if !ok {
+		log.Info("no types supplied for incoming annotation")
+		return "no types supplied"
+	}
+	id, ok := annoMap["id"].(string)
+	if !ok {
+		log.Info("no id supplied for incoming annotation")
+		return "no id supplied"
+	}
 
-		switch predicate {
-		case PredicateIsClassifiedBy:
-			if conceptType == ConceptTypeTopic || conceptType == ConceptTypeLocation {
-				annoMap["predicate"] = PredicateAbout
-			}
-		case PredicateIsPrimarilyClassifiedBy:
-			switch conceptType {
-			case ConceptTypeTopic, ConceptTypeLocation:
-				annoMap["predicate"] = PredicateAbout
-			case ConceptTypeBrand, ConceptTypeGenre:
-				annoMap["predicate"] = PredicateIsClassifiedBy
-			default:
-				continue
-			}
-		case PredicateMajorMentions:
-			annoMap["predicate"] = PredicateAbout
-		case PredicateImplicitlyAbout, PredicateImplicitlyClassifiedBy:
-			continue
-		default:
-			if !IsValidPACPredicate(predicate) {
-				log.Infof("Invalid PAC predicated not mapped: %s", predicate)
-				continue
-			}
-		}
+	annoMap["id"] = TransformConceptID(id)
 
-		convertedAnnotations = append(convertedAnnotations, annoMap)
-	}
+	stringTypes, _ := toStringArray(someTypes)
+	conceptType := getLeafType(stringTypes)
 
-	if len(convertedAnnotations) == 0 {
-		return nil, nil
+	annoMap["type"] = conceptType
+	delete(annoMap, "types")
+
+	if conceptType == ConceptTypeSpecialReport || conceptType == ConceptTypeSubject {
+		return fmt.Sprintf("concept type %s is not used in PAC", conceptType)
 	}
 
-	return json.Marshal(convertedAnnotations)
+	switch predicate {
+	case PredicateIsClassifiedBy:
+		if conceptType == ConceptTypeTopic || conceptType == ConceptTypeLocation {
+			annoMap["predicate"] = PredicateAbout
+		}
+	case PredicateIsPrimarilyClassifiedBy:
+		switch conceptType {
+		case ConceptTypeTopic, ConceptTypeLocation:
+			annoMap["predicate"] = PredicateAbout
+		case ConceptTypeBrand, ConceptTypeGenre:
+			annoMap["predicate"] = PredicateIsClassifiedBy
+		default:
+			return fmt.Sprintf("predicate %s is not used in PAC for concept type %s", predicate, conceptType)
+		}
+	case PredicateMajorMentions:
+		annoMap["predicate"] = PredicateAbout
+	case PredicateImplicitlyAbout, PredicateImplicitlyClassifiedBy:
+		return fmt.Sprintf("implicit predicate %s is not used in PAC", predicate)
+	default:
+		if !IsValidPACPredicate(predicate) {
+			log.Infof("Invalid PAC predicated not mapped: %s", predicate)
+			return fmt.Sprintf("predicate %s is not used in PAC", predicate)
+		}
+	}
+	return ""
 }
 
 func toStringArray(val interface{}) ([]string, error) {
@@ -117,6 +176,9 @@ func toStringArray(val interface{}) ([]string, error) {
 }
 
 func getLeafType(listOfTypes []string) string {
+	if len(listOfTypes) == 0 {
+		return ""
+	}
 	return listOfTypes[len(listOfTypes)-1]
 }
 
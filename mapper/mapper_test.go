@@ -46,3 +46,90 @@ func TestDiscardedAndEmpty(t *testing.T) {
 
 	assert.True(t, actualBody == nil, "some annotations have not been discarded")
 }
+
+func TestConvertPredicatesWithReport(t *testing.T) {
+	originalBody, err := ioutil.ReadFile("testdata/annotations_invalid_v2.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBody, err := ioutil.ReadFile("testdata/annotations_invalid_PAC.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actualBody, report, err := ConvertPredicatesWithReport(originalBody)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(expectedBody), string(actualBody))
+	assert.Equal(t, []Conversion{
+		{
+			Index:              0,
+			ID:                 "http://api.ft.com/things/13006c72-7d1b-47a0-96fe-d1ad1f12de9f",
+			Predicate:          PredicateIsClassifiedBy,
+			Action:             ConversionConverted,
+			ConvertedID:        "http://www.ft.com/thing/13006c72-7d1b-47a0-96fe-d1ad1f12de9f",
+			ConvertedPredicate: PredicateIsClassifiedBy,
+			Type:               ConceptTypeBrand,
+		},
+		{
+			Index:     1,
+			ID:        "http://api.ft.com/things/dbb0bdae-1f0c-11e4-b0cb-b2227cce2b54",
+			Predicate: "http://www.ft.com/ontology/invalidPredicate",
+			Action:    ConversionDropped,
+			Reason:    "predicate http://www.ft.com/ontology/invalidPredicate is not used in PAC",
+		},
+		{
+			Index:              2,
+			ID:                 "http://api.ft.com/things/29e67a92-a3b8-410c-9139-15abe9b47e12",
+			Predicate:          PredicateAbout,
+			Action:             ConversionConverted,
+			ConvertedID:        "http://www.ft.com/thing/29e67a92-a3b8-410c-9139-15abe9b47e12",
+			ConvertedPredicate: PredicateAbout,
+			Type:               ConceptTypeTopic,
+		},
+		{
+			Index:     3,
+			ID:        "http://api.ft.com/things/82645c31-4426-4ef5-99c9-9df6e0940c00",
+			Predicate: PredicateImplicitlyAbout,
+			Action:    ConversionDropped,
+			Reason:    "implicit predicate http://www.ft.com/ontology/implicitlyAbout is not used in PAC",
+		},
+	}, report)
+}
+
+func TestConvertPredicatesWithReportMissingFields(t *testing.T) {
+	body := `[
+		{"id": "http://api.ft.com/things/29e67a92-a3b8-410c-9139-15abe9b47e12", "types": ["http://www.ft.com/ontology/Topic"]},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "types": ["http://www.ft.com/ontology/Topic"]}
+	]`
+
+	actualBody, report, err := ConvertPredicatesWithReport([]byte(body))
+	assert.NoError(t, err)
+	assert.Nil(t, actualBody)
+	if assert.Len(t, report, 2) {
+		assert.Equal(t, "no predicate supplied", report[0].Reason)
+		assert.Equal(t, "no id supplied", report[1].Reason)
+		for _, c := range report {
+			assert.Equal(t, ConversionDropped, c.Action)
+		}
+	}
+}
+
+func TestConvertPredicatesWithReportUnexpectedTypes(t *testing.T) {
+	body := `[
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://api.ft.com/things/29e67a92-a3b8-410c-9139-15abe9b47e12", "types": "http://www.ft.com/ontology/Topic"},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://api.ft.com/things/29e67a92-a3b8-410c-9139-15abe9b47e13", "types": []}
+	]`
+
+	actualBody, report, err := ConvertPredicatesWithReport([]byte(body))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://www.ft.com/thing/29e67a92-a3b8-410c-9139-15abe9b47e12", "type": ""},
+		{"predicate": "http://www.ft.com/ontology/annotation/about", "id": "http://www.ft.com/thing/29e67a92-a3b8-410c-9139-15abe9b47e13", "type": ""}
+	]`, string(actualBody))
+	if assert.Len(t, report, 2) {
+		for _, c := range report {
+			assert.Equal(t, ConversionConverted, c.Action, "the annotations are converted without a type")
+			assert.Equal(t, "unexpected types property", c.Reason)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package mapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	uppConceptIDPrefix = "http://api.ft.com/things/"
+
+	conceptTypeThing          = "http://www.ft.com/ontology/core/Thing"
+	conceptTypeConcept        = "http://www.ft.com/ontology/concept/Concept"
+	conceptTypeClassification = "http://www.ft.com/ontology/classification/Classification"
+	conceptTypeOrganisation   = "http://www.ft.com/ontology/organisation/Organisation"
+	conceptTypeCompany        = "http://www.ft.com/ontology/company/Company"
+	conceptTypeIndustry       = "http://www.ft.com/ontology/industry/IndustryClassification"
+	conceptTypePerson         = "http://www.ft.com/ontology/person/Person"
+	conceptTypeSection        = "http://www.ft.com/ontology/Section"
+	conceptTypeAlphaville     = "http://www.ft.com/ontology/AlphavilleSeries"
+)
+
+// conceptTypeParents maps the concept types to their parent in the UPP ontology.
+// Types not listed here are considered direct children of Concept.
+var conceptTypeParents = map[string]string{
+	conceptTypeConcept:        conceptTypeThing,
+	conceptTypeClassification: conceptTypeConcept,
+	ConceptTypeBrand:          conceptTypeClassification,
+	ConceptTypeGenre:          conceptTypeClassification,
+	ConceptTypeTopic:          conceptTypeClassification,
+	ConceptTypeSpecialReport:  conceptTypeClassification,
+	ConceptTypeSubject:        conceptTypeClassification,
+	conceptTypeSection:        conceptTypeClassification,
+	conceptTypeAlphaville:     conceptTypeClassification,
+	ConceptTypeLocation:       conceptTypeConcept,
+	conceptTypeOrganisation:   conceptTypeConcept,
+	conceptTypeCompany:        conceptTypeOrganisation,
+	"http://www.ft.com/ontology/company/PublicCompany":  conceptTypeCompany,
+	"http://www.ft.com/ontology/company/PrivateCompany": conceptTypeCompany,
+	conceptTypePerson:   conceptTypeConcept,
+	conceptTypeIndustry: conceptTypeConcept,
+	"http://www.ft.com/ontology/industry/NAICSIndustryClassification": conceptTypeIndustry,
+}
+
+// ConvertToUPP converts PAC annotations back to the shape returned by the UPP annotations endpoint,
+// reversing ConvertPredicates: hasBrand becomes isClassifiedBy, concept IDs become UPP things URIs
+// and each type is expanded to its full types hierarchy.
+// The UPP predicates ConvertPredicates rewrites to about cannot be told apart any more, so about becomes
+// isClassifiedBy for topics and locations, and majorMentions otherwise, which both map back to about.
+func ConvertToUPP(body []byte) ([]byte, error) {
+	pacAnnotations := make([]map[string]interface{}, 0)
+	if err := json.Unmarshal(body, &pacAnnotations); err != nil {
+		return nil, fmt.Errorf("could not unmarshal json body:%w", err)
+	}
+
+	uppAnnotations := make([]map[string]interface{}, 0, len(pacAnnotations))
+	for _, annoMap := range pacAnnotations {
+		conceptType, _ := annoMap["type"].(string)
+		switch predicate, _ := annoMap["predicate"].(string); predicate {
+		case PredicateHasBrand:
+			annoMap["predicate"] = PredicateIsClassifiedBy
+		case PredicateAbout:
+			if conceptType == ConceptTypeTopic || conceptType == ConceptTypeLocation {
+				annoMap["predicate"] = PredicateIsClassifiedBy
+			} else {
+				annoMap["predicate"] = PredicateMajorMentions
+			}
+		}
+
+		if id, ok := annoMap["id"].(string); ok {
+			annoMap["id"] = toUPPConceptID(id)
+		}
+
+		annoMap["types"] = TypeHierarchy(conceptType)
+		delete(annoMap, "type")
+
+		uppAnnotations = append(uppAnnotations, annoMap)
+	}
+
+	return json.Marshal(uppAnnotations)
+}
+
+// TypeHierarchy returns the types of a concept of the given type, from the root of the UPP ontology down to the type itself.
+func TypeHierarchy(conceptType string) []string {
+	if conceptType == "" || conceptType == conceptTypeConcept {
+		return []string{conceptTypeThing, conceptTypeConcept}
+	}
+	if conceptType == conceptTypeThing {
+		return []string{conceptTypeThing}
+	}
+
+	hierarchy := []string{conceptType}
+	for t := conceptType; t != conceptTypeThing; {
+		parent, found := conceptTypeParents[t]
+		if !found {
+			parent = conceptTypeConcept
+		}
+		hierarchy = append([]string{parent}, hierarchy...)
+		t = parent
+	}
+	return hierarchy
+}
+
+func toUPPConceptID(id string) string {
+	i := strings.LastIndex(id, "/")
+	if i == -1 || i == len(id)-1 {
+		return id
+	}
+	return uppConceptIDPrefix + id[i+1:]
+}
@@ -0,0 +1,167 @@
+package mapper
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertToUPPRoundTrip(t *testing.T) {
+	tests := []struct {
+		name            string
+		fixtureBaseName string
+	}{
+		{"IsClassifiedBy", "annotations_isClassifiedBy"},
+		{"IsPrimarilyClassifiedBy", "annotations_isPrimarilyClassifiedBy"},
+		{"MajorMentions", "annotations_majorMentions"},
+		{"Defaults", "annotations_defaults"},
+		{"Invalid", "annotations_invalid"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pacBody, err := ioutil.ReadFile("testdata/" + test.fixtureBaseName + "_PAC.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			uppBody, err := ConvertToUPP(pacBody)
+			assert.NoError(t, err)
+
+			roundTripBody, err := ConvertPredicates(uppBody)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(pacBody), string(roundTripBody), "PAC annotations should survive the round trip")
+
+			// converting UPP annotations to PAC and back is stable as well
+			v2Body, err := ioutil.ReadFile("testdata/" + test.fixtureBaseName + "_v2.json")
+			if err != nil {
+				t.Fatal(err)
+			}
+			pacFromV2, err := ConvertPredicates(v2Body)
+			assert.NoError(t, err)
+			uppFromPAC, err := ConvertToUPP(pacFromV2)
+			assert.NoError(t, err)
+			pacAgain, err := ConvertPredicates(uppFromPAC)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(pacFromV2), string(pacAgain))
+		})
+	}
+}
+
+func TestConvertToUPP(t *testing.T) {
+	pacBody := `[
+		{
+			"predicate": "http://www.ft.com/ontology/hasBrand",
+			"id": "http://www.ft.com/thing/039d8d2c-c892-3793-ae67-684f104b0007",
+			"apiUrl": "http://api.ft.com/brands/039d8d2c-c892-3793-ae67-684f104b0007",
+			"type": "http://www.ft.com/ontology/product/Brand",
+			"prefLabel": "Week in Review"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+			"type": "http://www.ft.com/ontology/Location",
+			"prefLabel": "United Kingdom"
+		}
+	]`
+
+	uppBody, err := ConvertToUPP([]byte(pacBody))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `[
+		{
+			"predicate": "http://www.ft.com/ontology/classification/isClassifiedBy",
+			"id": "http://api.ft.com/things/039d8d2c-c892-3793-ae67-684f104b0007",
+			"apiUrl": "http://api.ft.com/brands/039d8d2c-c892-3793-ae67-684f104b0007",
+			"types": [
+				"http://www.ft.com/ontology/core/Thing",
+				"http://www.ft.com/ontology/concept/Concept",
+				"http://www.ft.com/ontology/classification/Classification",
+				"http://www.ft.com/ontology/product/Brand"
+			],
+			"prefLabel": "Week in Review"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/classification/isClassifiedBy",
+			"id": "http://api.ft.com/things/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+			"types": [
+				"http://www.ft.com/ontology/core/Thing",
+				"http://www.ft.com/ontology/concept/Concept",
+				"http://www.ft.com/ontology/Location"
+			],
+			"prefLabel": "United Kingdom"
+		}
+	]`, string(uppBody))
+}
+
+func TestConvertToUPPAboutRoundTrip(t *testing.T) {
+	pacBody := `[
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://www.ft.com/thing/1a2a1a0a-7199-38b8-8a73-e651e2172471",
+			"type": "http://www.ft.com/ontology/Location"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://www.ft.com/thing/7a8b0a33-3b0b-3a4e-8e1b-1f8e1b5b0c11",
+			"type": "http://www.ft.com/ontology/Topic"
+		},
+		{
+			"predicate": "http://www.ft.com/ontology/annotation/about",
+			"id": "http://www.ft.com/thing/0a619d71-9af5-3755-90dd-f789b686c67a",
+			"type": "http://www.ft.com/ontology/person/Person"
+		}
+	]`
+
+	uppBody, err := ConvertToUPP([]byte(pacBody))
+	assert.NoError(t, err)
+
+	var uppAnnotations []struct {
+		Predicate string `json:"predicate"`
+	}
+	assert.NoError(t, json.Unmarshal(uppBody, &uppAnnotations))
+	assert.Equal(t, PredicateIsClassifiedBy, uppAnnotations[0].Predicate)
+	assert.Equal(t, PredicateIsClassifiedBy, uppAnnotations[1].Predicate)
+	assert.Equal(t, PredicateMajorMentions, uppAnnotations[2].Predicate)
+
+	roundTripBody, err := ConvertPredicates(uppBody)
+	assert.NoError(t, err)
+	assert.JSONEq(t, pacBody, string(roundTripBody))
+}
+
+func TestConvertToUPPInvalidBody(t *testing.T) {
+	_, err := ConvertToUPP([]byte(`{"annotations":[]}`))
+	assert.Error(t, err)
+}
+
+func TestTypeHierarchy(t *testing.T) {
+	tests := map[string][]string{
+		"": {
+			"http://www.ft.com/ontology/core/Thing",
+			"http://www.ft.com/ontology/concept/Concept",
+		},
+		"http://www.ft.com/ontology/company/PublicCompany": {
+			"http://www.ft.com/ontology/core/Thing",
+			"http://www.ft.com/ontology/concept/Concept",
+			"http://www.ft.com/ontology/organisation/Organisation",
+			"http://www.ft.com/ontology/company/Company",
+			"http://www.ft.com/ontology/company/PublicCompany",
+		},
+		"http://www.ft.com/ontology/Topic": {
+			"http://www.ft.com/ontology/core/Thing",
+			"http://www.ft.com/ontology/concept/Concept",
+			"http://www.ft.com/ontology/classification/Classification",
+			"http://www.ft.com/ontology/Topic",
+		},
+		"http://www.ft.com/ontology/someNewConceptType": {
+			"http://www.ft.com/ontology/core/Thing",
+			"http://www.ft.com/ontology/concept/Concept",
+			"http://www.ft.com/ontology/someNewConceptType",
+		},
+	}
+
+	for conceptType, expected := range tests {
+		assert.Equal(t, expected, TypeHierarchy(conceptType), conceptType)
+	}
+}
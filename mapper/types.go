@@ -0,0 +1,32 @@
+package mapper
+
+// predicateConceptTypes lists the concept types that can be annotated with each PAC predicate.
+// The subtypes of the listed types, e.g. the companies of Organisation, can be annotated as well.
+var predicateConceptTypes = map[string][]string{
+	PredicateAbout:          {ConceptTypeTopic, ConceptTypeLocation, conceptTypePerson, conceptTypeOrganisation, conceptTypeIndustry},
+	PredicateMentions:       {ConceptTypeTopic, ConceptTypeLocation, conceptTypePerson, conceptTypeOrganisation, conceptTypeIndustry},
+	PredicateHasDisplayTag:  {ConceptTypeTopic, ConceptTypeLocation, conceptTypePerson, conceptTypeOrganisation, conceptTypeIndustry},
+	PredicateHasAuthor:      {conceptTypePerson},
+	PredicateHasContributor: {conceptTypePerson},
+	PredicateHasBrand:       {ConceptTypeBrand},
+	PredicateIsClassifiedBy: {ConceptTypeBrand, ConceptTypeGenre, conceptTypeSection, conceptTypeAlphaville},
+}
+
+// ConceptTypesForPredicate returns the concept types that can be annotated with the PAC predicate,
+// or nil if the predicate is not a PAC predicate.
+func ConceptTypesForPredicate(predicate string) []string {
+	return predicateConceptTypes[predicate]
+}
+
+// IsValidConceptTypeForPredicate tells whether a concept of the given type can be annotated with the PAC predicate.
+func IsValidConceptTypeForPredicate(predicate string, conceptType string) bool {
+	valid := predicateConceptTypes[predicate]
+	for _, t := range TypeHierarchy(conceptType) {
+		for _, v := range valid {
+			if t == v {
+				return true
+			}
+		}
+	}
+	return false
+}
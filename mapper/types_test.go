@@ -0,0 +1,31 @@
+package mapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidConceptTypeForPredicate(t *testing.T) {
+	tests := []struct {
+		predicate   string
+		conceptType string
+		valid       bool
+	}{
+		{PredicateHasAuthor, "http://www.ft.com/ontology/person/Person", true},
+		{PredicateHasAuthor, ConceptTypeTopic, false},
+		{PredicateAbout, ConceptTypeTopic, true},
+		{PredicateMentions, "http://www.ft.com/ontology/company/PublicCompany", true},
+		{PredicateHasBrand, ConceptTypeBrand, true},
+		{PredicateHasBrand, ConceptTypeGenre, false},
+		{PredicateIsClassifiedBy, ConceptTypeGenre, true},
+		{PredicateIsClassifiedBy, ConceptTypeLocation, false},
+		{"http://www.ft.com/ontology/annotation/invalid", ConceptTypeTopic, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.valid, IsValidConceptTypeForPredicate(test.predicate, test.conceptType), "%s %s", test.predicate, test.conceptType)
+	}
+	assert.Equal(t, []string{"http://www.ft.com/ontology/person/Person"}, ConceptTypesForPredicate(PredicateHasContributor))
+	assert.Nil(t, ConceptTypesForPredicate("http://www.ft.com/ontology/annotation/invalid"))
+}
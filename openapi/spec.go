@@ -0,0 +1,261 @@
+package openapi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Route identifies an endpoint by HTTP method and path template.
+// Path parameters can be written either as {name}, as in the spec, or as :name, as in the router.
+type Route struct {
+	Method string
+	Path   string
+}
+
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// normalised returns the route with an upper case method and with unnamed path parameters,
+// so that routes from the spec and from the router can be compared.
+func (r Route) normalised() Route {
+	segments := strings.Split(r.Path, "/")
+	for i, s := range segments {
+		if isPathParam(s) {
+			segments[i] = "{}"
+		}
+	}
+	return Route{Method: strings.ToUpper(r.Method), Path: strings.Join(segments, "/")}
+}
+
+func isPathParam(segment string) bool {
+	return strings.HasPrefix(segment, ":") || (strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"))
+}
+
+type document struct {
+	Paths       map[string]map[string]*operation `yaml:"paths"`
+	Definitions map[string]*schema               `yaml:"definitions"`
+}
+
+type operation struct {
+	Parameters []parameter `yaml:"parameters"`
+}
+
+type parameter struct {
+	Name     string        `yaml:"name"`
+	In       string        `yaml:"in"`
+	Required bool          `yaml:"required"`
+	Type     string        `yaml:"type"`
+	Format   string        `yaml:"format"`
+	Enum     []interface{} `yaml:"enum"`
+	Items    *schema       `yaml:"items"`
+	Schema   *schema       `yaml:"schema"`
+}
+
+type schema struct {
+	Ref        string             `yaml:"$ref"`
+	Type       string             `yaml:"type"`
+	Format     string             `yaml:"format"`
+	Enum       []interface{}      `yaml:"enum"`
+	Properties map[string]*schema `yaml:"properties"`
+	Required   []string           `yaml:"required"`
+	Items      *schema            `yaml:"items"`
+}
+
+// Spec is the subset of a Swagger 2.0 specification needed to validate requests.
+type Spec struct {
+	paths []*path
+}
+
+type path struct {
+	template   string
+	segments   []string
+	operations map[string]*operation
+}
+
+// Load reads the Swagger 2.0 specification at the given location.
+func Load(location string) (*Spec, error) {
+	b, err := ioutil.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(b)
+}
+
+// Parse parses a Swagger 2.0 specification.
+func Parse(b []byte) (*Spec, error) {
+	var doc document
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse API spec: %w", err)
+	}
+
+	spec := &Spec{}
+	for template, methods := range doc.Paths {
+		p := &path{
+			template:   template,
+			segments:   strings.Split(template, "/"),
+			operations: make(map[string]*operation),
+		}
+		for method, op := range methods {
+			if op == nil {
+				op = &operation{}
+			}
+			if err := resolveOperationRefs(op, doc.Definitions); err != nil {
+				return nil, fmt.Errorf("invalid %s %s in API spec: %w", strings.ToUpper(method), template, err)
+			}
+			p.operations[strings.ToUpper(method)] = op
+		}
+		spec.paths = append(spec.paths, p)
+	}
+	// prefer the most specific templates, i.e. the ones with the most literal segments
+	sort.Slice(spec.paths, func(i, j int) bool {
+		return literalSegments(spec.paths[i].segments) > literalSegments(spec.paths[j].segments)
+	})
+	return spec, nil
+}
+
+// definitionRefPrefix prefixes the references to the definitions of the spec, the only references supported.
+const definitionRefPrefix = "#/definitions/"
+
+func resolveOperationRefs(op *operation, definitions map[string]*schema) error {
+	for i := range op.Parameters {
+		p := &op.Parameters[i]
+		var err error
+		if p.Items, err = resolveRefs(p.Items, definitions, map[*schema]bool{}); err != nil {
+			return err
+		}
+		if p.Schema, err = resolveRefs(p.Schema, definitions, map[*schema]bool{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveRefs replaces the references to definitions in the schema and its subschemas by the definitions themselves.
+// Resolved is the set of schemas already resolved, which makes recursive definitions safe.
+func resolveRefs(s *schema, definitions map[string]*schema, resolved map[*schema]bool) (*schema, error) {
+	if s == nil {
+		return nil, nil
+	}
+	if s.Ref != "" {
+		def, found := definitions[strings.TrimPrefix(s.Ref, definitionRefPrefix)]
+		if !strings.HasPrefix(s.Ref, definitionRefPrefix) || !found || def == nil {
+			return nil, fmt.Errorf("unresolvable reference %q", s.Ref)
+		}
+		s = def
+	}
+	if resolved[s] {
+		return s, nil
+	}
+	resolved[s] = true
+
+	var err error
+	for name, property := range s.Properties {
+		if s.Properties[name], err = resolveRefs(property, definitions, resolved); err != nil {
+			return nil, err
+		}
+	}
+	if s.Items, err = resolveRefs(s.Items, definitions, resolved); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func literalSegments(segments []string) int {
+	n := 0
+	for _, s := range segments {
+		if !isPathParam(s) {
+			n++
+		}
+	}
+	return n
+}
+
+// Routes returns all the routes described by the spec.
+func (s *Spec) Routes() []Route {
+	var routes []Route
+	for _, p := range s.paths {
+		for method := range p.operations {
+			routes = append(routes, Route{Method: method, Path: p.template})
+		}
+	}
+	sortRoutes(routes)
+	return routes
+}
+
+// CheckRoutes returns an error listing the differences between the routes described by the spec and the registered ones.
+func (s *Spec) CheckRoutes(registered []Route) error {
+	specRoutes := make(map[Route]Route)
+	for _, r := range s.Routes() {
+		specRoutes[r.normalised()] = r
+	}
+
+	var missingInSpec []Route
+	for _, r := range registered {
+		n := r.normalised()
+		if _, found := specRoutes[n]; !found {
+			missingInSpec = append(missingInSpec, r)
+		}
+		delete(specRoutes, n)
+	}
+
+	var notRegistered []Route
+	for _, r := range specRoutes {
+		notRegistered = append(notRegistered, r)
+	}
+
+	if len(missingInSpec) == 0 && len(notRegistered) == 0 {
+		return nil
+	}
+
+	sortRoutes(missingInSpec)
+	sortRoutes(notRegistered)
+	return fmt.Errorf("API spec does not match the registered routes: not in spec %v, not registered %v", missingInSpec, notRegistered)
+}
+
+func sortRoutes(routes []Route) {
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+}
+
+// find returns the operation described for the given method and request path, along with the values of its path parameters.
+func (s *Spec) find(method string, requestPath string) (*operation, map[string]string, bool) {
+	segments := strings.Split(requestPath, "/")
+	for _, p := range s.paths {
+		params, ok := p.match(segments)
+		if !ok {
+			continue
+		}
+		op, found := p.operations[method]
+		return op, params, found
+	}
+	return nil, nil, false
+}
+
+func (p *path) match(segments []string) (map[string]string, bool) {
+	if len(segments) != len(p.segments) {
+		return nil, false
+	}
+	params := make(map[string]string)
+	for i, s := range p.segments {
+		if isPathParam(s) {
+			if segments[i] == "" {
+				return nil, false
+			}
+			params[strings.Trim(s, "{}")] = segments[i]
+			continue
+		}
+		if s != segments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
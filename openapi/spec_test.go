@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const apiYml = "../_ft/api.yml"
+
+var registeredRoutes = []Route{
+	{"DELETE", "/drafts/content/:uuid/annotations/:cuuid"},
+	{"GET", "/drafts/content/:uuid/annotations"},
+	{"PUT", "/drafts/content/:uuid/annotations"},
+	{"POST", "/drafts/content/:uuid/annotations"},
+	{"POST", "/drafts/content/:uuid/annotations/promote"},
+	{"GET", "/drafts/content/:uuid/annotations/suggestions"},
+	{"GET", "/drafts/concepts/search"},
+	{"GET", "/drafts/concepts/:cuuid/content"},
+	{"PATCH", "/drafts/content/:uuid/annotations/:cuuid"},
+	{"POST", "/__admin/concepts/replace"},
+	{"DELETE", "/__admin/published-annotations/:uuid"},
+	{"POST", "/__admin/concept-index/rebuild"},
+	{"GET", "/__health"},
+	{"GET", "/__gtg"},
+	{"GET", "/__build-info"},
+	{"GET", "/__metrics"},
+}
+
+func TestLoad(t *testing.T) {
+	spec, err := Load(apiYml)
+	assert.NoError(t, err)
+	assert.Len(t, spec.Routes(), len(registeredRoutes))
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	_, err := Load("./missing.yml")
+	assert.Error(t, err)
+}
+
+func TestParseInvalidSpec(t *testing.T) {
+	_, err := Parse([]byte("paths: [not a map"))
+	assert.Error(t, err)
+}
+
+func TestCheckRoutes(t *testing.T) {
+	spec, err := Load(apiYml)
+	assert.NoError(t, err)
+
+	assert.NoError(t, spec.CheckRoutes(registeredRoutes))
+
+	err = spec.CheckRoutes(append(registeredRoutes, Route{"GET", "/drafts/content/:uuid/annotations/:cuuid"}))
+	assert.EqualError(t, err, "API spec does not match the registered routes: not in spec [GET /drafts/content/:uuid/annotations/:cuuid], not registered []")
+
+	err = spec.CheckRoutes(registeredRoutes[1:])
+	assert.EqualError(t, err, "API spec does not match the registered routes: not in spec [], not registered [DELETE /drafts/content/{uuid}/annotations/{conceptUUID}]")
+}
+
+func TestFindPrefersLiteralSegments(t *testing.T) {
+	spec, err := Parse([]byte(`
+paths:
+  /things/{uuid}:
+    get:
+      parameters:
+        - name: uuid
+          in: path
+  /things/search:
+    get: {}
+`))
+	assert.NoError(t, err)
+
+	op, params, found := spec.find("GET", "/things/search")
+	assert.True(t, found)
+	assert.Empty(t, op.Parameters)
+	assert.Empty(t, params)
+
+	op, params, found = spec.find("GET", "/things/8df16ae8-0dfd-4859-a5ff-eeb9644bed35")
+	assert.True(t, found)
+	assert.Len(t, op.Parameters, 1)
+	assert.Equal(t, map[string]string{"uuid": "8df16ae8-0dfd-4859-a5ff-eeb9644bed35"}, params)
+
+	_, _, found = spec.find("PUT", "/things/search")
+	assert.False(t, found)
+}
+
+func TestParseResolvesDefinitionRefs(t *testing.T) {
+	spec, err := Parse([]byte(`
+paths:
+  /things:
+    put:
+      parameters:
+        - name: body
+          in: body
+          schema:
+            type: array
+            items:
+              $ref: "#/definitions/Thing"
+definitions:
+  Thing:
+    type: object
+    properties:
+      kind:
+        $ref: "#/definitions/Kind"
+      children:
+        type: array
+        items:
+          $ref: "#/definitions/Thing"
+  Kind:
+    type: string
+    enum:
+      - big
+      - small
+`))
+	assert.NoError(t, err)
+
+	op, _, found := spec.find("PUT", "/things")
+	if assert.True(t, found) {
+		thing := op.Parameters[0].Schema.Items
+		assert.Equal(t, "object", thing.Type)
+		assert.Equal(t, []interface{}{"big", "small"}, thing.Properties["kind"].Enum)
+		assert.True(t, thing == thing.Properties["children"].Items, "recursive definitions should be resolved to themselves")
+	}
+}
+
+func TestParseUnresolvableRef(t *testing.T) {
+	_, err := Parse([]byte(`
+paths:
+  /things:
+    put:
+      parameters:
+        - name: body
+          in: body
+          schema:
+            $ref: "#/definitions/Missing"
+`))
+	assert.EqualError(t, err, `invalid PUT /things in API spec: unresolvable reference "#/definitions/Missing"`)
+}
@@ -0,0 +1,276 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	uuid "github.com/satori/go.uuid"
+)
+
+// FieldError describes why the value of a request field does not match the spec.
+// Field is prefixed by the location of the value, e.g. path.uuid, query.sendHasBrand or body.annotations[0].id.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError collects all the mismatches between a request and the spec.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("request does not match the API spec: %v", e.Errors)
+}
+
+func (e *ValidationError) add(field string, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Validate checks the path, query parameters and body of the request against the operation described in the spec.
+// Requests for paths or methods not described in the spec are not validated.
+// The request body is left available to be read again.
+func (s *Spec) Validate(r *http.Request) error {
+	op, pathParams, found := s.find(r.Method, r.URL.Path)
+	if !found {
+		return nil
+	}
+
+	verr := &ValidationError{}
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			validateSimpleValue(verr, "path."+p.Name, pathParams[p.Name], p)
+		case "query":
+			values, present := r.URL.Query()[p.Name]
+			if !present {
+				if p.Required {
+					verr.add("query."+p.Name, "is required")
+				}
+				continue
+			}
+			validateQueryValues(verr, "query."+p.Name, values, p)
+		case "body":
+			if err := validateBody(verr, r, p); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(verr.Errors) == 0 {
+		return nil
+	}
+	return verr
+}
+
+func validateQueryValues(verr *ValidationError, field string, values []string, p parameter) {
+	if p.Type == "array" {
+		if p.Items == nil {
+			return
+		}
+		items := parameter{Type: p.Items.Type, Format: p.Items.Format, Enum: p.Items.Enum}
+		for i, v := range values {
+			validateSimpleValue(verr, fmt.Sprintf("%s[%d]", field, i), v, items)
+		}
+		return
+	}
+	if len(values) > 1 {
+		verr.add(field, "must be provided only once")
+		return
+	}
+	validateSimpleValue(verr, field, values[0], p)
+}
+
+func validateSimpleValue(verr *ValidationError, field string, value string, p parameter) {
+	switch p.Type {
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			verr.add(field, "must be a boolean")
+			return
+		}
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			verr.add(field, "must be an integer")
+			return
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			verr.add(field, "must be a number")
+			return
+		}
+	}
+	if !validFormat(p.Format, value) {
+		verr.add(field, "must be a valid %s", p.Format)
+		return
+	}
+	if len(p.Enum) > 0 && !inEnum(p.Enum, value) {
+		verr.add(field, "must be one of %v", p.Enum)
+	}
+}
+
+func validateBody(verr *ValidationError, r *http.Request, p parameter) error {
+	var body []byte
+	if r.Body != nil {
+		b, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+		body = b
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if p.Required {
+			verr.add("body", "is required")
+		}
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		verr.add("body", "must be valid JSON")
+		return nil
+	}
+	if p.Schema != nil && isJSONBody(r) {
+		validateSchema(verr, "body", value, p.Schema)
+	}
+	return nil
+}
+
+// isJSONBody tells whether the body schema applies to the request body.
+// The schema describes application/json bodies, so UPP format bodies, given either with their own media type
+// or with the format=upp query parameter, only need to be valid JSON.
+func isJSONBody(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "upp" {
+		return false
+	}
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err != nil || mediaType == "application/json"
+}
+
+func validateSchema(verr *ValidationError, field string, value interface{}, s *schema) {
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			verr.add(field, "must be an object")
+			return
+		}
+		for _, name := range s.Required {
+			if _, found := obj[name]; !found {
+				verr.add(field+"."+name, "is required")
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, found := obj[name]; found {
+				validateSchema(verr, field+"."+name, v, s.Properties[name])
+			}
+		}
+		return
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			verr.add(field, "must be an array")
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				validateSchema(verr, fmt.Sprintf("%s[%d]", field, i), item, s.Items)
+			}
+		}
+		return
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			verr.add(field, "must be a string")
+			return
+		}
+		if !validFormat(s.Format, str) {
+			verr.add(field, "must be a valid %s", s.Format)
+			return
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			verr.add(field, "must be a boolean")
+			return
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			verr.add(field, "must be a %s", s.Type)
+			return
+		}
+		if s.Type == "integer" && n != float64(int64(n)) {
+			verr.add(field, "must be an integer")
+			return
+		}
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		verr.add(field, "must be one of %v", s.Enum)
+	}
+}
+
+func validFormat(format string, value string) bool {
+	switch format {
+	case "uuid":
+		_, err := uuid.FromString(value)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	v := fmt.Sprint(value)
+	for _, e := range enum {
+		if fmt.Sprint(e) == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Problem is the RFC 7807 body of the responses to the requests that do not match the spec, listing every mismatch.
+type Problem struct {
+	problem.Problem
+
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// Middleware rejects with a 400 application/problem+json response, listing every mismatch, the requests that do not match the spec.
+func (s *Spec) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := s.Validate(r)
+		if err == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		p := &Problem{Problem: *problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, "Request does not match the API spec")}
+		p.TransactionID = r.Header.Get(tidutils.TransactionIDHeader)
+		if verr, ok := err.(*ValidationError); ok {
+			p.Errors = verr.Errors
+		} else {
+			p.Detail = "Failed to read request: " + err.Error()
+		}
+		problem.Write(w, p)
+	})
+}
@@ -0,0 +1,185 @@
+package openapi
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Financial-Times/draft-annotations-api/problem"
+	tidutils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	contentPath = "/drafts/content/8df16ae8-0dfd-4859-a5ff-eeb9644bed35/annotations"
+	conceptPath = contentPath + "/0667615f-499e-4fa6-8130-f3430450228d"
+)
+
+func TestMiddleware(t *testing.T) {
+	spec, err := Load(apiYml)
+	assert.NoError(t, err)
+
+	tests := map[string]struct {
+		method         string
+		path           string
+		contentType    string
+		body           string
+		expectedStatus int
+		expectedErrors []FieldError
+	}{
+		"valid read": {
+			method:         "GET",
+			path:           contentPath + "?sendHasBrand=true",
+			expectedStatus: http.StatusOK,
+		},
+		"invalid content UUID": {
+			method:         "GET",
+			path:           "/drafts/content/not-a-uuid/annotations",
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"path.uuid", "must be a valid uuid"}},
+		},
+		"invalid query parameter": {
+			method:         "GET",
+			path:           contentPath + "?sendHasBrand=maybe",
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"query.sendHasBrand", "must be a boolean"}},
+		},
+		"valid write": {
+			method:         "PUT",
+			path:           contentPath,
+			body:           `{"annotations":[{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"http://www.ft.com/ontology/annotation/about"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		"invalid write": {
+			method:         "PUT",
+			path:           contentPath,
+			body:           `{"annotations":[{"predicate":"http://www.ft.com/ontology/annotation/about"},{"id":42,"predicate":"http://www.ft.com/ontology/annotation/about"}]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{
+				{"body.annotations[0].id", "is required"},
+				{"body.annotations[1].id", "must be a string"},
+			},
+		},
+		"UPP write as a bare array": {
+			method:         "PUT",
+			path:           contentPath,
+			contentType:    "application/vnd.ft-upp-annotations+json",
+			body:           `[{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"about"}]`,
+			expectedStatus: http.StatusOK,
+		},
+		"malformed UPP write": {
+			method:         "PUT",
+			path:           contentPath,
+			contentType:    "application/vnd.ft-upp-annotations+json",
+			body:           `[{"id":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"body", "must be valid JSON"}},
+		},
+		"JSON write as a bare array": {
+			method:         "PUT",
+			path:           contentPath,
+			contentType:    "application/json; charset=utf-8",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"body", "must be an object"}},
+		},
+		"write with a predicate not valid in PAC": {
+			method:         "PUT",
+			path:           contentPath,
+			body:           `{"annotations":[{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"http://www.ft.com/ontology/invalidPredicate"}]}`,
+			expectedStatus: http.StatusOK,
+		},
+		"UPP format write without content type": {
+			method:         "PUT",
+			path:           contentPath + "?format=upp",
+			body:           `[{"id":"http://api.ft.com/things/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"http://www.ft.com/ontology/annotation/majorMentions"}]`,
+			expectedStatus: http.StatusOK,
+		},
+		"UPP format write with JSON content type": {
+			method:         "PUT",
+			path:           contentPath + "?format=upp",
+			contentType:    "application/json",
+			body:           `[{"id":"http://api.ft.com/things/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"http://www.ft.com/ontology/annotation/majorMentions"}]`,
+			expectedStatus: http.StatusOK,
+		},
+		"malformed UPP format write": {
+			method:         "PUT",
+			path:           contentPath + "?format=upp",
+			body:           `[{"id":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"body", "must be valid JSON"}},
+		},
+		"missing body": {
+			method:         "PUT",
+			path:           contentPath,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"body", "is required"}},
+		},
+		"malformed body": {
+			method:         "POST",
+			path:           contentPath,
+			body:           `{"id":`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{{"body", "must be valid JSON"}},
+		},
+		"invalid concept UUID and body": {
+			method:         "PATCH",
+			path:           contentPath + "/not-a-uuid",
+			body:           `[]`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErrors: []FieldError{
+				{"path.conceptUUID", "must be a valid uuid"},
+				{"body", "must be an object"},
+			},
+		},
+		"valid delete": {
+			method:         "DELETE",
+			path:           conceptPath,
+			expectedStatus: http.StatusOK,
+		},
+		"path not in spec": {
+			method:         "GET",
+			path:           "/__api",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var receivedBody string
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := ioutil.ReadAll(r.Body)
+				receivedBody = string(b)
+			})
+
+			req := httptest.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			req.Header.Set(tidutils.TransactionIDHeader, "tid_test")
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			w := httptest.NewRecorder()
+			spec.Middleware(next).ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+			assert.Equal(t, test.expectedStatus, resp.StatusCode)
+
+			if test.expectedStatus == http.StatusOK {
+				assert.Equal(t, test.body, receivedBody, "the body should be passed on to the next handler")
+				return
+			}
+
+			assert.Equal(t, problem.ContentType, resp.Header.Get("Content-Type"))
+			var p Problem
+			assert.NoError(t, json.NewDecoder(resp.Body).Decode(&p))
+			assert.Equal(t, "Request does not match the API spec", p.Detail)
+			assert.Equal(t, problem.CodeInvalidRequest, p.Code)
+			assert.Equal(t, http.StatusBadRequest, p.Status)
+			assert.Equal(t, "tid_test", p.TransactionID)
+			assert.Equal(t, test.expectedErrors, p.Errors)
+		})
+	}
+}
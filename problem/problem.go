@@ -0,0 +1,55 @@
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ContentType is the media type of the error responses, as defined by RFC 7807.
+const ContentType = "application/problem+json"
+
+// CodeInvalidRequest is the code of the requests that are malformed or do not match the API spec.
+const CodeInvalidRequest = "invalid_request"
+
+// Problem is the RFC 7807 body of the error responses.
+// Code identifies the error and is stable, unlike Detail which is meant for humans.
+type Problem struct {
+	Type          string `json:"type"`
+	Title         string `json:"title"`
+	Status        int    `json:"status"`
+	Detail        string `json:"detail,omitempty"`
+	Code          string `json:"code"`
+	TransactionID string `json:"transactionId,omitempty"`
+	Upstream      string `json:"upstream,omitempty"`
+}
+
+// Body is a Problem, or a struct embedding one to add members of its own.
+type Body interface {
+	problem() *Problem
+}
+
+func (p *Problem) problem() *Problem {
+	return p
+}
+
+// New returns the problem with the given status, code and detail.
+func New(status int, code string, detail string) *Problem {
+	return &Problem{Type: "about:blank", Title: http.StatusText(status), Status: status, Code: code, Detail: detail}
+}
+
+// Write writes the problem response, with the status and title of the problem.
+func Write(w http.ResponseWriter, body Body) {
+	p := body.problem()
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	p.Title = http.StatusText(p.Status)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.WithError(err).Error("Failed to write problem response.")
+	}
+}
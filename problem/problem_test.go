@@ -0,0 +1,48 @@
+package problem
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrite(t *testing.T) {
+	p := New(http.StatusBadRequest, CodeInvalidRequest, "invalid body")
+	p.Status = http.StatusGatewayTimeout
+	p.TransactionID = "tid_test"
+
+	w := httptest.NewRecorder()
+	Write(w, p)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	assert.Equal(t, ContentType, w.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Gateway Timeout",
+		"status": 504,
+		"detail": "invalid body",
+		"code": "invalid_request",
+		"transactionId": "tid_test"
+	}`, w.Body.String())
+}
+
+func TestWriteExtendedProblem(t *testing.T) {
+	body := struct {
+		Problem
+		Errors []string `json:"errors"`
+	}{Problem: *New(http.StatusBadRequest, CodeInvalidRequest, ""), Errors: []string{"body is required"}}
+
+	w := httptest.NewRecorder()
+	Write(w, &body)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Bad Request",
+		"status": 400,
+		"code": "invalid_request",
+		"errors": ["body is required"]
+	}`, w.Body.String())
+}
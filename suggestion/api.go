@@ -0,0 +1,85 @@
+package suggestion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	log "github.com/sirupsen/logrus"
+)
+
+const apiKeyHeader = "X-Api-Key"
+
+// ErrUnexpectedResponse is returned when the suggestion service responds with an unexpected HTTP status code.
+var ErrUnexpectedResponse = errors.New("suggestion service returned an unexpected HTTP status code")
+
+// API gets the concepts suggested to annotate a content with.
+type API interface {
+	GetSuggestions(ctx context.Context, contentUUID string) ([]Suggestion, error)
+	Endpoint() string
+}
+
+type suggestionAPI struct {
+	endpointTemplate string
+	apiKey           string
+	httpClient       *http.Client
+}
+
+// NewAPI initializes an API calling the suggestion service by given http client,
+// the url template of its endpoint for the suggestions of a content and API key.
+func NewAPI(client *http.Client, endpointTemplate string, apiKey string) API {
+	return &suggestionAPI{endpointTemplate: endpointTemplate, apiKey: apiKey, httpClient: client}
+}
+
+// GetSuggestions returns the suggestions of the suggestion service for the content,
+// which are none when the service does not know the content.
+func (api *suggestionAPI) GetSuggestions(ctx context.Context, contentUUID string) ([]Suggestion, error) {
+	tid, err := tidUtils.GetTransactionIDFromContext(ctx)
+	if err != nil {
+		tid = "not_found"
+	}
+	reqURI := fmt.Sprintf(api.endpointTemplate, contentUUID)
+	logEntry := log.WithField(tidUtils.TransactionIDKey, tid).WithField("url", reqURI).WithField("uuid", contentUUID)
+
+	req, err := http.NewRequest("GET", reqURI, nil)
+	if err != nil {
+		logEntry.WithError(err).Error("Error in creating the HTTP request to the suggestion service")
+		return nil, err
+	}
+	req.Header.Set(apiKeyHeader, api.apiKey)
+	req.Header.Set(tidUtils.TransactionIDHeader, tid)
+
+	logEntry.Info("Calling the suggestion service")
+	resp, err := api.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		logEntry.WithError(err).Error("Error making the HTTP request to the suggestion service")
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return []Suggestion{}, nil
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		err = fmt.Errorf("status %d %s: %w", resp.StatusCode, string(body), ErrUnexpectedResponse)
+		logEntry.WithError(err).Error("Error received from the suggestion service")
+		return nil, err
+	}
+
+	var result Result
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		logEntry.WithError(err).Error("Error in unmarshalling the HTTP response from the suggestion service")
+		return nil, err
+	}
+	return result.Suggestions, nil
+}
+
+func (api *suggestionAPI) Endpoint() string {
+	return api.endpointTemplate
+}
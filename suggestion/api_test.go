@@ -0,0 +1,66 @@
+package suggestion
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Financial-Times/go-ft-http/fthttp"
+	tidUtils "github.com/Financial-Times/transactionid-utils-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAPIKey      = "testAPIKey"
+	testContentUUID = "8df16ae8-0dfd-4859-a5ff-eeb9644bed35"
+)
+
+var testClient = fthttp.NewClientWithDefaultTimeout("PAC", "draft-annotations-api")
+
+func newSuggestionServiceMock(t *testing.T, status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/content/"+testContentUUID+"/suggestions", r.URL.Path)
+		assert.Equal(t, testAPIKey, r.Header.Get(apiKeyHeader))
+		assert.Equal(t, "tid_test", r.Header.Get(tidUtils.TransactionIDHeader))
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+}
+
+func TestGetSuggestions(t *testing.T) {
+	s := newSuggestionServiceMock(t, http.StatusOK, `{"suggestions":[{"id":"http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a","predicate":"http://www.ft.com/ontology/annotation/mentions","score":0.87}]}`)
+	defer s.Close()
+
+	api := NewAPI(testClient, s.URL+"/content/%v/suggestions", testAPIKey)
+	suggestions, err := api.GetSuggestions(tidUtils.TransactionAwareContext(context.Background(), "tid_test"), testContentUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, []Suggestion{
+		{
+			ConceptId: "http://www.ft.com/thing/d7113d1d-ed66-3adf-9910-1f62b2c40e6a",
+			Predicate: "http://www.ft.com/ontology/annotation/mentions",
+			Score:     0.87,
+		},
+	}, suggestions)
+	assert.Equal(t, s.URL+"/content/%v/suggestions", api.Endpoint())
+}
+
+func TestGetSuggestionsUnknownContent(t *testing.T) {
+	s := newSuggestionServiceMock(t, http.StatusNotFound, "not found")
+	defer s.Close()
+
+	api := NewAPI(testClient, s.URL+"/content/%v/suggestions", testAPIKey)
+	suggestions, err := api.GetSuggestions(tidUtils.TransactionAwareContext(context.Background(), "tid_test"), testContentUUID)
+	assert.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
+
+func TestGetSuggestionsUnexpectedStatus(t *testing.T) {
+	s := newSuggestionServiceMock(t, http.StatusServiceUnavailable, "unavailable")
+	defer s.Close()
+
+	api := NewAPI(testClient, s.URL+"/content/%v/suggestions", testAPIKey)
+	_, err := api.GetSuggestions(tidUtils.TransactionAwareContext(context.Background(), "tid_test"), testContentUUID)
+	assert.True(t, errors.Is(err, ErrUnexpectedResponse))
+}
@@ -0,0 +1,14 @@
+package suggestion
+
+// Result models the data returned from the suggestion service.
+type Result struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestion is a concept the suggestion service proposes to annotate a content with, using the given predicate.
+// The higher the score, the stronger the suggestion.
+type Suggestion struct {
+	ConceptId string  `json:"id"`
+	Predicate string  `json:"predicate"`
+	Score     float64 `json:"score"`
+}